@@ -1,21 +1,57 @@
 package corbado
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/corbado/webhook-go/pkg/action"
+	"github.com/corbado/webhook-go/pkg/audit"
+	"github.com/corbado/webhook-go/pkg/auth"
+	"github.com/corbado/webhook-go/pkg/bruteforce"
 	"github.com/corbado/webhook-go/pkg/callback"
+	"github.com/corbado/webhook-go/pkg/core"
+	"github.com/corbado/webhook-go/pkg/credential"
+	"github.com/corbado/webhook-go/pkg/hooks"
+	"github.com/corbado/webhook-go/pkg/ipfilter"
+	"github.com/corbado/webhook-go/pkg/lockout"
 	"github.com/corbado/webhook-go/pkg/logger"
+	"github.com/corbado/webhook-go/pkg/replay"
+	"github.com/corbado/webhook-go/pkg/responseid"
+	"github.com/corbado/webhook-go/pkg/signature"
 )
 
 type Builder struct {
 	logger                 logger.Logger
 	username               string
 	password               string
-	authMethodsCallback    callback.AuthMethods
-	passwordVerifyCallback callback.PasswordVerify
+	usernameHash           string
+	passwordHash           string
+	authMethodsCallback    callback.AuthMethodsWithResult
+	passwordVerifyCallback callback.PasswordVerifyWithResult
+	actions                map[string]action.Handler
+	responseIDGenerator    responseid.Generator
+	errorFormat            core.ErrorFormat
+	timeouts               map[string]*core.Timeout
+	minDurations           map[string]*core.MinDuration
+	hooks                  []*hooks.Hooks
+	maxBodySize            int64
+	credentials            []*credential.Credential
+	signatureVerifier      *signature.Verifier
+	authenticator          auth.Authenticator
+	ipFilter               *ipfilter.Filter
+	clientIPResolver       *ipfilter.Resolver
+	replayGuard            *replay.Guard
+	lockout                *lockout.Lockout
+	passwordVerifyLimiter  *bruteforce.Limiter
+	tracerProvider         trace.TracerProvider
+	propagator             propagation.TextMapPropagator
+	auditor                *audit.Auditor
 }
 
-// NewBuilder returns new builder instance.
+// NewBuilder returns new builder instance (the zero value of Builder can be used too).
 func NewBuilder() *Builder {
 	return &Builder{}
 }
@@ -41,41 +77,370 @@ func (b *Builder) SetPassword(password string) *Builder {
 	return b
 }
 
-// SetAuthMethodsCallback sets given callback on builder.
+// SetUsernameHash sets given username hash on builder (instead of SetUsername()). See
+// credential.NewFromHashes() for the supported hashes.
+func (b *Builder) SetUsernameHash(usernameHash string) *Builder {
+	b.usernameHash = usernameHash
+
+	return b
+}
+
+// SetPasswordHash sets given password hash on builder (instead of SetPassword()), so the plaintext webhook
+// password is not needed. See credential.NewFromHashes() for the supported hashes.
+func (b *Builder) SetPasswordHash(passwordHash string) *Builder {
+	b.passwordHash = passwordHash
+
+	return b
+}
+
+// AddCredential adds given credential (accepted Basic Auth username/password pair) on builder. Credentials
+// can be used instead of or in addition to SetUsername() and SetPassword(), for example to accept the old and
+// the new password while rotating the webhook password.
+func (b *Builder) AddCredential(credential *credential.Credential) *Builder {
+	b.credentials = append(b.credentials, credential)
+
+	return b
+}
+
+// SetAuthenticator sets given authenticator on builder, it replaces Basic Auth with username/password and
+// credentials (which cannot be set then, use auth.NewBasic() to combine Basic Auth with other authenticators
+// via auth.All() or auth.Any()).
+func (b *Builder) SetAuthenticator(authenticator auth.Authenticator) *Builder {
+	b.authenticator = authenticator
+
+	return b
+}
+
+// SetSignatureVerifier sets given signature verifier on builder, all requests must be signed then (see
+// signature.NewSigner()). If no username/password and no credentials are set only the signature is verified,
+// otherwise Basic Auth and signature must both be valid.
+func (b *Builder) SetSignatureVerifier(verifier *signature.Verifier) *Builder {
+	b.signatureVerifier = verifier
+
+	return b
+}
+
+// SetIPFilter sets given IP filter on builder, requests from client addresses which are not allowed are
+// rejected with status code 403 before authentication. Keep a reference to the filter to update the allowed
+// ranges at runtime (see ipfilter.Filter.Update()).
+func (b *Builder) SetIPFilter(filter *ipfilter.Filter) *Builder {
+	b.ipFilter = filter
+
+	return b
+}
+
+// SetClientIPResolver sets given client IP resolver on builder, it resolves the client address of requests
+// through trusted proxies (see ipfilter.NewResolver()) without restricting client addresses. Not needed if an
+// IP filter is set, which resolves client addresses itself.
+func (b *Builder) SetClientIPResolver(resolver *ipfilter.Resolver) *Builder {
+	b.clientIPResolver = resolver
+
+	return b
+}
+
+// SetLockout sets given lockout on builder, client addresses are locked (429 with Retry-After) after repeated
+// authentication failures (Basic Auth or other authenticator and signature). Behind proxies set an IP filter
+// or a client IP resolver, otherwise all requests share the address of the proxy.
+func (b *Builder) SetLockout(lockout *lockout.Lockout) *Builder {
+	b.lockout = lockout
+
+	return b
+}
+
+// SetPasswordVerifyLimiter sets given limiter on builder, it wraps the passwordVerify callback to limit failed
+// attempts per project and username (not applied to a passwordVerify action registered with RegisterAction()).
+func (b *Builder) SetPasswordVerifyLimiter(limiter *bruteforce.Limiter) *Builder {
+	b.passwordVerifyLimiter = limiter
+
+	return b
+}
+
+// SetTracerProvider sets given tracer provider on builder, it creates a server span per webhook request with
+// child spans for authentication, decoding and the callback. The context passed to callbacks carries the
+// callback span. If not set the global tracer provider (see otel.GetTracerProvider()) is used.
+func (b *Builder) SetTracerProvider(tracerProvider trace.TracerProvider) *Builder {
+	b.tracerProvider = tracerProvider
+
+	return b
+}
+
+// SetPropagator sets given propagator on builder, it extracts the trace context from the request headers. If
+// not set W3C trace context is used.
+func (b *Builder) SetPropagator(propagator propagation.TextMapPropagator) *Builder {
+	b.propagator = propagator
+
+	return b
+}
+
+// SetAuditor sets given auditor on builder, it writes an audit record (with pseudonymized username) for every
+// webhook request. Records are written before the response is sent, so with a file sink and audit.SyncAlways
+// (the default) every response waits for the flush to disk, use audit.SyncInterval for high request rates.
+func (b *Builder) SetAuditor(auditor *audit.Auditor) *Builder {
+	b.auditor = auditor
+
+	return b
+}
+
+// SetReplayGuard sets given replay guard on builder, it remembers the ids of requests to reject duplicates
+// (or replay the first response, depending on the mode of the guard).
+func (b *Builder) SetReplayGuard(guard *replay.Guard) *Builder {
+	b.replayGuard = guard
+
+	return b
+}
+
+// SetAuthMethodsCallback sets given callback on builder (replaces a callback set with one of the other
+// SetAuthMethods*Callback() methods).
 func (b *Builder) SetAuthMethodsCallback(authMethodsCallback callback.AuthMethods) *Builder {
+	b.authMethodsCallback = callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(authMethodsCallback))
+
+	return b
+}
+
+// SetAuthMethodsContextCallback sets given context-aware callback on builder (replaces a callback set with
+// one of the other SetAuthMethods*Callback() methods).
+func (b *Builder) SetAuthMethodsContextCallback(authMethodsCallback callback.AuthMethodsContext) *Builder {
+	b.authMethodsCallback = callback.AdaptAuthMethodsContext(authMethodsCallback)
+
+	return b
+}
+
+// SetAuthMethodsResultCallback sets given callback returning a result (with responseID) on builder (replaces
+// a callback set with one of the other SetAuthMethods*Callback() methods).
+func (b *Builder) SetAuthMethodsResultCallback(authMethodsCallback callback.AuthMethodsWithResult) *Builder {
 	b.authMethodsCallback = authMethodsCallback
 
 	return b
 }
 
-// SetPasswordVerifyCallback sets given callback on builder.
+// SetPasswordVerifyCallback sets given callback on builder (replaces a callback set with one of the other
+// SetPasswordVerify*Callback() methods).
 func (b *Builder) SetPasswordVerifyCallback(passwordVerifyCallback callback.PasswordVerify) *Builder {
+	b.passwordVerifyCallback = callback.AdaptPasswordVerifyContext(callback.AdaptPasswordVerify(passwordVerifyCallback))
+
+	return b
+}
+
+// SetPasswordVerifyContextCallback sets given context-aware callback on builder (replaces a callback set with
+// one of the other SetPasswordVerify*Callback() methods).
+func (b *Builder) SetPasswordVerifyContextCallback(passwordVerifyCallback callback.PasswordVerifyContext) *Builder {
+	b.passwordVerifyCallback = callback.AdaptPasswordVerifyContext(passwordVerifyCallback)
+
+	return b
+}
+
+// SetPasswordVerifyResultCallback sets given callback returning a result (with responseID) on builder (replaces
+// a callback set with one of the other SetPasswordVerify*Callback() methods).
+func (b *Builder) SetPasswordVerifyResultCallback(passwordVerifyCallback callback.PasswordVerifyWithResult) *Builder {
 	b.passwordVerifyCallback = passwordVerifyCallback
 
 	return b
 }
 
+// SetResponseIDGenerator sets given generator on builder, it is used to generate a responseID for every
+// request (see responseid.NewUUID(), responseid.NewULID() and responseid.NewFromRequest()). The responseID
+// is added to the log output of the request and used in the response unless the callback returns its own.
+func (b *Builder) SetResponseIDGenerator(generator responseid.Generator) *Builder {
+	b.responseIDGenerator = generator
+
+	return b
+}
+
+// RegisterAction registers given handler for given action (value of the X-Corbado-Action header) on builder.
+// Registering a handler for a built-in action ('authMethods' or 'passwordVerify') replaces the built-in
+// handler, the corresponding callback is not needed then.
+func (b *Builder) RegisterAction(name string, handler action.Handler) *Builder {
+	if b.actions == nil {
+		b.actions = make(map[string]action.Handler)
+	}
+
+	b.actions[name] = handler
+
+	return b
+}
+
+// SetErrorFormat sets given error format on builder. By default errors are sent as JSON, use
+// core.ErrorFormatText for the legacy plain text format.
+func (b *Builder) SetErrorFormat(errorFormat core.ErrorFormat) *Builder {
+	b.errorFormat = errorFormat
+
+	return b
+}
+
+// SetActionTimeout sets given timeout for given action on builder. If the callback (handler) of the action does
+// not finish in time the given fallback is used to respond (see action.NewErrorFallback(),
+// action.NewAuthMethodsFallback() and action.NewPasswordVerifyFallback()), if fallback is nil status code
+// 504 is sent. The callback keeps running in the background, its context is canceled though.
+func (b *Builder) SetActionTimeout(name string, timeout time.Duration, fallback action.Fallback) *Builder {
+	if b.timeouts == nil {
+		b.timeouts = make(map[string]*core.Timeout)
+	}
+
+	b.timeouts[name] = &core.Timeout{
+		Duration: timeout,
+		Fallback: fallback,
+	}
+
+	return b
+}
+
+// SetMinResponseTime sets the minimum response time of given action on builder, faster responses (including
+// error responses) are delayed to duration plus a random jitter (measured from the start of the request) so
+// the response time does not reveal whether a user exists.
+func (b *Builder) SetMinResponseTime(name string, duration time.Duration, jitter time.Duration) *Builder {
+	if b.minDurations == nil {
+		b.minDurations = make(map[string]*core.MinDuration)
+	}
+
+	b.minDurations[name] = &core.MinDuration{
+		Duration: duration,
+		Jitter:   jitter,
+	}
+
+	return b
+}
+
+// AddHooks adds given hooks on builder, they are called in the order they were added.
+func (b *Builder) AddHooks(hooks *hooks.Hooks) *Builder {
+	b.hooks = append(b.hooks, hooks)
+
+	return b
+}
+
+// SetMaxBodySize sets given maximum size of request bodies (in bytes) on builder. Larger bodies are rejected
+// with status code 413, defaults to core.DefaultMaxBodySize.
+func (b *Builder) SetMaxBodySize(maxBodySize int64) *Builder {
+	b.maxBodySize = maxBodySize
+
+	return b
+}
+
 // Build builds a webhook instance, first validating all given parameters.
 func (b *Builder) Build() (Webhook, error) {
 	if b.logger == nil {
 		return nil, errors.New("logger cannot be empty, call SetLogger() with logger")
 	}
 
-	if b.username == "" {
-		return nil, errors.New("username cannot be empty, call SetUsername() with username")
+	credentials := make([]*credential.Credential, 0, len(b.credentials)+1)
+	defaultCredentialRequired := len(b.credentials) == 0 && b.signatureVerifier == nil && b.authenticator == nil
+	if defaultCredentialRequired || b.username != "" || b.password != "" || b.usernameHash != "" || b.passwordHash != "" {
+		cred, err := b.buildDefaultCredential()
+		if err != nil {
+			return nil, err
+		}
+
+		credentials = append(credentials, cred)
 	}
 
-	if b.password == "" {
-		return nil, errors.New("password cannot be empty, call SetPassword() with password")
+	for _, cred := range b.credentials {
+		if cred == nil {
+			return nil, errors.New("credential cannot be empty, call AddCredential() with credential")
+		}
+
+		credentials = append(credentials, cred)
 	}
 
-	if b.authMethodsCallback == nil {
-		return nil, errors.New("authMethodsCallback cannot be empty, call SetAuthMethodsCallback() with callback")
+	if b.authenticator != nil && len(credentials) > 0 {
+		return nil, errors.New("authenticator and credentials cannot both be set, use auth.NewBasic() to combine Basic Auth with the authenticator")
 	}
 
-	if b.passwordVerifyCallback == nil {
-		return nil, errors.New("passwordVerifyCallback cannot be empty, call SetPasswordVerifyCallback() with callback")
+	if b.ipFilter != nil && b.clientIPResolver != nil {
+		return nil, errors.New("ipFilter and clientIPResolver cannot both be set, call either SetIPFilter() or SetClientIPResolver()")
+	}
+
+	actions := make(map[string]action.Handler, len(b.actions)+2)
+	for name, handler := range b.actions {
+		if name == "" {
+			return nil, errors.New("action name cannot be empty, call RegisterAction() with name")
+		}
+
+		if handler == nil {
+			return nil, errors.Errorf("handler for action '%s' cannot be empty, call RegisterAction() with handler", name)
+		}
+
+		actions[name] = handler
+	}
+
+	if _, exists := actions[action.AuthMethods]; !exists {
+		if b.authMethodsCallback == nil {
+			return nil, errors.New("authMethodsCallback cannot be empty, call one of the SetAuthMethods*Callback() methods with callback")
+		}
+
+		handler, err := action.NewAuthMethods(b.authMethodsCallback)
+		if err != nil {
+			return nil, err
+		}
+
+		actions[action.AuthMethods] = handler
+	}
+
+	if _, exists := actions[action.PasswordVerify]; !exists {
+		if b.passwordVerifyCallback == nil {
+			return nil, errors.New("passwordVerifyCallback cannot be empty, call one of the SetPasswordVerify*Callback() methods with callback")
+		}
+
+		passwordVerifyCallback := b.passwordVerifyCallback
+		if b.passwordVerifyLimiter != nil {
+			passwordVerifyCallback = b.passwordVerifyLimiter.Wrap(passwordVerifyCallback)
+		}
+
+		handler, err := action.NewPasswordVerify(passwordVerifyCallback)
+		if err != nil {
+			return nil, err
+		}
+
+		actions[action.PasswordVerify] = handler
+	}
+
+	return newFromConfig(&core.Config{
+		Logger:              b.logger,
+		Credentials:         credentials,
+		Authenticator:       b.authenticator,
+		SignatureVerifier:   b.signatureVerifier,
+		IPFilter:            b.ipFilter,
+		ClientIPResolver:    b.clientIPResolver,
+		Lockout:             b.lockout,
+		ReplayGuard:         b.replayGuard,
+		Actions:             actions,
+		ResponseIDGenerator: b.responseIDGenerator,
+		ErrorFormat:         b.errorFormat,
+		Timeouts:            b.timeouts,
+		MinDurations:        b.minDurations,
+		TracerProvider:      b.tracerProvider,
+		Propagator:          b.propagator,
+		Auditor:             b.auditor,
+		Hooks:               b.hooks,
+		MaxBodySize:         b.maxBodySize,
+	})
+}
+
+// buildDefaultCredential builds the credential from username (hash) and password (hash).
+func (b *Builder) buildDefaultCredential() (*credential.Credential, error) {
+	if b.username != "" && b.usernameHash != "" {
+		return nil, errors.New("username and usernameHash cannot both be set, call either SetUsername() or SetUsernameHash()")
+	}
+
+	if b.password != "" && b.passwordHash != "" {
+		return nil, errors.New("password and passwordHash cannot both be set, call either SetPassword() or SetPasswordHash()")
+	}
+
+	usernameHash := b.usernameHash
+	if usernameHash == "" {
+		if b.username == "" {
+			return nil, errors.New("username cannot be empty, call SetUsername() with username")
+		}
+
+		usernameHash = credential.HashSHA256(b.username)
+	}
+
+	passwordHash := b.passwordHash
+	if passwordHash == "" {
+		if b.password == "" {
+			return nil, errors.New("password cannot be empty, call SetPassword() with password")
+		}
+
+		passwordHash = credential.HashSHA256(b.password)
 	}
 
-	return New(b.logger, b.username, b.password, b.authMethodsCallback, b.passwordVerifyCallback)
+	return credential.NewFromHashes(credential.DefaultLabel, usernameHash, passwordHash, time.Time{}, time.Time{})
 }
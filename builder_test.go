@@ -0,0 +1,253 @@
+package corbado_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	corbado "github.com/corbado/webhook-go"
+	"github.com/corbado/webhook-go/pkg/auth"
+	"github.com/corbado/webhook-go/pkg/credential"
+	"github.com/corbado/webhook-go/pkg/ipfilter"
+	"github.com/corbado/webhook-go/pkg/logger"
+)
+
+func TestBuilderZeroValue(t *testing.T) {
+	builder := &corbado.Builder{}
+
+	webhook, err := builder.
+		SetLogger(logger.NewNull()).
+		SetUsername(username).
+		SetPassword(password).
+		SetAuthMethodsCallback(authMethodsCallback).
+		SetPasswordVerifyCallback(passwordVerifyCallback).
+		RegisterAction("echo", echoAction(t)).
+		SetActionTimeout("echo", time.Second, nil).
+		SetMinResponseTime("echo", time.Millisecond, 0).
+		Build()
+	require.NoError(t, err)
+	assert.NotNil(t, webhook)
+}
+
+func TestBuilderHashes(t *testing.T) {
+	passwordHash, err := credential.HashBcrypt(password, bcrypt.MinCost)
+	require.NoError(t, err)
+
+	_, err = corbado.
+		NewBuilder().
+		SetLogger(logger.NewNull()).
+		SetUsername(username).
+		SetPassword(password).
+		SetPasswordHash(passwordHash).
+		SetAuthMethodsCallback(authMethodsCallback).
+		SetPasswordVerifyCallback(passwordVerifyCallback).
+		Build()
+	assert.ErrorContains(t, err, "password and passwordHash cannot both be set")
+
+	webhook, err := corbado.
+		NewBuilder().
+		SetLogger(logger.NewNull()).
+		SetUsernameHash(credential.HashSHA256(username)).
+		SetPasswordHash(passwordHash).
+		SetAuthMethodsCallback(authMethodsCallback).
+		SetPasswordVerifyCallback(passwordVerifyCallback).
+		Build()
+	require.NoError(t, err)
+
+	handler, err := webhook.GetStandardHandler()
+	require.NoError(t, err)
+
+	body, err := os.ReadFile("testdata/authMethodsRequest.json")
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		password   string
+		statusCode int
+	}{
+		{password: "invalidPassword", statusCode: http.StatusUnauthorized},
+		{password: password, statusCode: http.StatusOK},
+		{password: password, statusCode: http.StatusOK},
+	} {
+		r, err := http.NewRequest("POST", "/webhook", bytes.NewReader(body))
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, test.password)
+		r.Header.Set("X-Corbado-Action", "authMethods")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		assert.Equal(t, test.statusCode, rr.Code)
+	}
+}
+
+func TestBuilderClientIPResolver(t *testing.T) {
+	filter, err := ipfilter.New([]string{"192.0.2.0/24"}, nil, ipfilter.ForwardedHeaderXForwardedFor)
+	require.NoError(t, err)
+
+	resolver, err := ipfilter.NewResolver([]string{"10.0.0.0/8"}, ipfilter.ForwardedHeaderXForwardedFor)
+	require.NoError(t, err)
+
+	_, err = corbado.
+		NewBuilder().
+		SetLogger(logger.NewNull()).
+		SetUsername(username).
+		SetPassword(password).
+		SetIPFilter(filter).
+		SetClientIPResolver(resolver).
+		SetAuthMethodsCallback(authMethodsCallback).
+		SetPasswordVerifyCallback(passwordVerifyCallback).
+		Build()
+	assert.ErrorContains(t, err, "ipFilter and clientIPResolver cannot both be set")
+
+	_, err = corbado.
+		NewBuilder().
+		SetLogger(logger.NewNull()).
+		SetUsername(username).
+		SetPassword(password).
+		SetClientIPResolver(resolver).
+		SetAuthMethodsCallback(authMethodsCallback).
+		SetPasswordVerifyCallback(passwordVerifyCallback).
+		Build()
+	require.NoError(t, err)
+}
+
+func TestBuilderIPFilter(t *testing.T) {
+	filter, err := ipfilter.New([]string{"192.0.2.0/24"}, []string{"10.0.0.0/8"}, ipfilter.ForwardedHeaderXForwardedFor)
+	require.NoError(t, err)
+
+	webhook, err := corbado.
+		NewBuilder().
+		SetLogger(logger.NewNull()).
+		SetUsername(username).
+		SetPassword(password).
+		SetIPFilter(filter).
+		SetAuthMethodsCallback(authMethodsCallback).
+		SetPasswordVerifyCallback(passwordVerifyCallback).
+		Build()
+	require.NoError(t, err)
+
+	standardHandler, err := webhook.GetStandardHandler()
+	require.NoError(t, err)
+
+	ginHandler, err := webhook.GetGinHandler()
+	require.NoError(t, err)
+
+	gin.SetMode(gin.ReleaseMode)
+
+	ginRouter := gin.New()
+	ginRouter.POST("/webhook", ginHandler.Handle)
+
+	body, err := os.ReadFile("testdata/authMethodsRequest.json")
+	require.NoError(t, err)
+
+	newRequest := func(remoteAddr string, forwardedFor string) *http.Request {
+		r := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+		r.RemoteAddr = remoteAddr
+		r.SetBasicAuth(username, password)
+		r.Header.Set("X-Corbado-Action", "authMethods")
+
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+
+		return r
+	}
+
+	for _, handler := range []http.Handler{standardHandler, ginRouter} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, newRequest("192.0.2.1:1234", ""))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, newRequest("198.51.100.1:1234", "192.0.2.1"))
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, newRequest("10.0.0.1:1234", "192.0.2.1"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		// Forwarded header set by the client is passed through by the X-Forwarded-For proxy
+		r := newRequest("10.0.0.1:1234", "198.51.100.9")
+		r.Header.Set("Forwarded", "for=192.0.2.1")
+
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	}
+
+	require.NoError(t, filter.Update([]string{"198.51.100.0/24"}, nil))
+
+	rr := httptest.NewRecorder()
+	standardHandler.ServeHTTP(rr, newRequest("192.0.2.1:1234", ""))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = httptest.NewRecorder()
+	standardHandler.ServeHTTP(rr, newRequest("198.51.100.1:1234", ""))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestBuilderAuthenticator(t *testing.T) {
+	bearer, err := auth.NewBearer(map[string]string{"ingress": "secretToken"})
+	require.NoError(t, err)
+
+	_, err = corbado.
+		NewBuilder().
+		SetLogger(logger.NewNull()).
+		SetUsername(username).
+		SetPassword(password).
+		SetAuthenticator(bearer).
+		SetAuthMethodsCallback(authMethodsCallback).
+		SetPasswordVerifyCallback(passwordVerifyCallback).
+		Build()
+	assert.ErrorContains(t, err, "authenticator and credentials cannot both be set")
+
+	webhook, err := corbado.
+		NewBuilder().
+		SetLogger(logger.NewNull()).
+		SetAuthenticator(bearer).
+		SetAuthMethodsCallback(authMethodsCallback).
+		SetPasswordVerifyCallback(passwordVerifyCallback).
+		Build()
+	require.NoError(t, err)
+
+	standardHandler, err := webhook.GetStandardHandler()
+	require.NoError(t, err)
+
+	ginHandler, err := webhook.GetGinHandler()
+	require.NoError(t, err)
+
+	gin.SetMode(gin.ReleaseMode)
+
+	ginRouter := gin.New()
+	ginRouter.POST("/webhook", ginHandler.Handle)
+
+	body, err := os.ReadFile("testdata/authMethodsRequest.json")
+	require.NoError(t, err)
+
+	newRequest := func(authorization string) *http.Request {
+		r := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+		r.Header.Set("Authorization", authorization)
+		r.Header.Set("X-Corbado-Action", "authMethods")
+
+		return r
+	}
+
+	for _, handler := range []http.Handler{standardHandler, ginRouter} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, newRequest("Bearer secretToken"))
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, newRequest("Bearer wrong"))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, `Bearer realm="restricted"`, rr.Header().Get("WWW-Authenticate"))
+	}
+}
@@ -23,11 +23,14 @@ func main() {
 		SetPassword(webhookPassword).
 		SetAuthMethodsCallback(authMethodsCallback).
 		SetPasswordVerifyCallback(passwordVerifyCallback).
+		// Errors are sent as JSON, uncomment to send plain text instead (legacy format)
+		// SetErrorFormat(core.ErrorFormatText).
 		Build()
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// Use ginhandler.NewFromCore() to create the handler from your own core.Core instead
 	handler, err := webhook.GetGinHandler()
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,143 @@
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Handler handles a single webhook action (selected by the X-Corbado-Action header). Errors of type
+// webhookerr.Error are sent back with their status code and public message, other errors returned by
+// Validate are sent back as bad request and all other errors result in an internal server error. The
+// context given to Handle is derived from the HTTP request. Decode should decode while reading from the body
+// (instead of reading it completely first), the body is limited to the configured maximum size.
+type Handler interface {
+	Decode(body io.Reader) (any, error)
+	Validate(request any) error
+	Handle(ctx context.Context, request any) (any, error)
+	Encode(response any) ([]byte, error)
+}
+
+// Identifiable can be implemented by decoded requests to expose their id and project id (used for
+// responseID generation for example).
+type Identifiable interface {
+	GetID() string
+	GetProjectID() string
+}
+
+// UsernameGetter can be implemented by decoded requests to expose the username they refer to (pseudonymized
+// in audit records).
+type UsernameGetter interface {
+	GetUsername() string
+}
+
+// ResponseIdentifiable can be implemented by responses to expose their responseID (used for the request logger,
+// hooks, tracing and audit records, as callbacks can set their own responseID).
+type ResponseIdentifiable interface {
+	GetResponseID() string
+}
+
+// Outcomer can be implemented by responses to expose the outcome of the action (logged and used for metrics,
+// for example "exists" for action 'authMethods').
+type Outcomer interface {
+	Outcome() string
+}
+
+type Typed[Req any, Resp any] struct {
+	decode   func(body io.Reader) (Req, error)
+	validate func(request Req) error
+	handle   func(ctx context.Context, request Req) (Resp, error)
+	encode   func(response Resp) ([]byte, error)
+}
+
+var _ Handler = &Typed[any, any]{}
+
+// New returns new action handler built from the given typed functions. The validate function is optional
+// and can be nil, use EncodeJSON as encode function for JSON responses.
+func New[Req any, Resp any](
+	decode func(body io.Reader) (Req, error),
+	validate func(request Req) error,
+	handle func(ctx context.Context, request Req) (Resp, error),
+	encode func(response Resp) ([]byte, error),
+) (*Typed[Req, Resp], error) {
+	if decode == nil {
+		return nil, errors.New("empty parameter decode")
+	}
+
+	if handle == nil {
+		return nil, errors.New("empty parameter handle")
+	}
+
+	if encode == nil {
+		return nil, errors.New("empty parameter encode")
+	}
+
+	return &Typed[Req, Resp]{
+		decode:   decode,
+		validate: validate,
+		handle:   handle,
+		encode:   encode,
+	}, nil
+}
+
+// EncodeJSON encodes given response as JSON.
+func EncodeJSON[Resp any](response Resp) ([]byte, error) {
+	marshaled, err := json.Marshal(response)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return marshaled, nil
+}
+
+// Decode decodes the given request body.
+func (t *Typed[Req, Resp]) Decode(body io.Reader) (any, error) {
+	return t.decode(body)
+}
+
+// Validate validates the given (decoded) request.
+func (t *Typed[Req, Resp]) Validate(request any) error {
+	if t.validate == nil {
+		return nil
+	}
+
+	req, err := assertType[Req](request)
+	if err != nil {
+		return err
+	}
+
+	return t.validate(req)
+}
+
+// Handle executes the action for the given (decoded) request.
+func (t *Typed[Req, Resp]) Handle(ctx context.Context, request any) (any, error) {
+	req, err := assertType[Req](request)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.handle(ctx, req)
+}
+
+// Encode encodes the given response.
+func (t *Typed[Req, Resp]) Encode(response any) ([]byte, error) {
+	resp, err := assertType[Resp](response)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.encode(resp)
+}
+
+func assertType[T any](value any) (T, error) {
+	typed, ok := value.(T)
+	if !ok {
+		var zero T
+
+		return zero, errors.Errorf("unexpected type %T, expected %T", value, zero)
+	}
+
+	return typed, nil
+}
@@ -0,0 +1,75 @@
+package action_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/action"
+)
+
+type request struct {
+	Value string
+}
+
+type response struct {
+	Value string `json:"value"`
+}
+
+func TestNew(t *testing.T) {
+	decode := func(body io.Reader) (*request, error) {
+		value, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+
+		return &request{Value: string(value)}, nil
+	}
+	handle := func(_ context.Context, req *request) (*response, error) {
+		return &response{Value: strings.ToUpper(req.Value)}, nil
+	}
+
+	handler, err := action.New(nil, nil, handle, action.EncodeJSON[*response])
+	assert.ErrorContains(t, err, "empty parameter decode")
+	assert.Nil(t, handler)
+
+	handler, err = action.New(decode, nil, nil, action.EncodeJSON[*response])
+	assert.ErrorContains(t, err, "empty parameter handle")
+	assert.Nil(t, handler)
+
+	handler, err = action.New[*request, *response](decode, nil, handle, nil)
+	assert.ErrorContains(t, err, "empty parameter encode")
+	assert.Nil(t, handler)
+
+	handler, err = action.New(decode, func(req *request) error {
+		if req.Value == "" {
+			return errors.New("value must not be empty")
+		}
+
+		return nil
+	}, handle, action.EncodeJSON[*response])
+	require.NoError(t, err)
+
+	req, err := handler.Decode(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.ErrorContains(t, handler.Validate(req), "value must not be empty")
+
+	req, err = handler.Decode(strings.NewReader("test"))
+	require.NoError(t, err)
+	assert.NoError(t, handler.Validate(req))
+
+	resp, err := handler.Handle(context.Background(), req)
+	require.NoError(t, err)
+
+	encoded, err := handler.Encode(resp)
+	require.NoError(t, err)
+	assert.Equal(t, `{"value":"TEST"}`, string(encoded))
+
+	_, err = handler.Handle(context.Background(), "invalid")
+	assert.ErrorContains(t, err, "unexpected type string, expected *action_test.request")
+}
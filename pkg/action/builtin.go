@@ -0,0 +1,105 @@
+package action
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/corbado/webhook-go/pkg/callback"
+	"github.com/corbado/webhook-go/pkg/dto/authmethodsrequest"
+	"github.com/corbado/webhook-go/pkg/dto/authmethodsresponse"
+	"github.com/corbado/webhook-go/pkg/dto/passwordverifyrequest"
+	"github.com/corbado/webhook-go/pkg/dto/passwordverifyresponse"
+	"github.com/corbado/webhook-go/pkg/responseid"
+)
+
+const (
+	AuthMethods    = "authMethods"
+	PasswordVerify = "passwordVerify"
+)
+
+// NewAuthMethods returns the built-in handler for action 'authMethods' which executes the given callback.
+// If the callback does not return a responseID the one from the context (see responseid.FromContext) is used.
+func NewAuthMethods(authMethodsCallback callback.AuthMethodsWithResult) (Handler, error) {
+	if authMethodsCallback == nil {
+		return nil, errors.New("empty parameter authMethodsCallback")
+	}
+
+	handler, err := New(
+		authmethodsrequest.NewFromReader,
+		func(req *authmethodsrequest.DTO) error {
+			if req.Data.Username == "" {
+				return errors.New("username must not be empty")
+			}
+
+			return nil
+		},
+		func(ctx context.Context, req *authmethodsrequest.DTO) (*authmethodsresponse.DTO, error) {
+			result, err := authMethodsCallback(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+
+			if result == nil {
+				return nil, errors.New("authMethodsCallback returned empty result")
+			}
+
+			return authmethodsresponse.New(responseID(ctx, result.ResponseID), result.Status)
+		},
+		EncodeJSON[*authmethodsresponse.DTO],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+// NewPasswordVerify returns the built-in handler for action 'passwordVerify' which executes the given callback.
+// If the callback does not return a responseID the one from the context (see responseid.FromContext) is used.
+func NewPasswordVerify(passwordVerifyCallback callback.PasswordVerifyWithResult) (Handler, error) {
+	if passwordVerifyCallback == nil {
+		return nil, errors.New("empty parameter passwordVerifyCallback")
+	}
+
+	handler, err := New(
+		passwordverifyrequest.NewFromReader,
+		func(req *passwordverifyrequest.DTO) error {
+			if req.Data.Username == "" {
+				return errors.New("username must not be empty")
+			}
+
+			if req.Data.Password == "" {
+				return errors.New("password must not be empty")
+			}
+
+			return nil
+		},
+		func(ctx context.Context, req *passwordverifyrequest.DTO) (*passwordverifyresponse.DTO, error) {
+			result, err := passwordVerifyCallback(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+
+			if result == nil {
+				return nil, errors.New("passwordVerifyCallback returned empty result")
+			}
+
+			return passwordverifyresponse.New(responseID(ctx, result.ResponseID), result.Success)
+		},
+		EncodeJSON[*passwordverifyresponse.DTO],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler, nil
+}
+
+func responseID(ctx context.Context, fromResult string) string {
+	if fromResult != "" {
+		return fromResult
+	}
+
+	return responseid.FromContext(ctx)
+}
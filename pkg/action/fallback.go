@@ -0,0 +1,43 @@
+package action
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/corbado/webhook-go/pkg/dto/authmethodsresponse"
+	"github.com/corbado/webhook-go/pkg/dto/passwordverifyresponse"
+	"github.com/corbado/webhook-go/pkg/responseid"
+	"github.com/corbado/webhook-go/pkg/webhookerr"
+)
+
+// Fallback returns the response (or error) which is used if the handler of an action does not finish within
+// its timeout. The given context is not canceled by the timeout.
+type Fallback func(ctx context.Context, request any) (any, error)
+
+// NewErrorFallback returns fallback which returns given error (for example webhookerr.Unavailable()).
+func NewErrorFallback(err error) Fallback {
+	return func(_ context.Context, _ any) (any, error) {
+		return nil, err
+	}
+}
+
+// NewDefaultFallback returns fallback which returns status code 504.
+func NewDefaultFallback() Fallback {
+	return NewErrorFallback(webhookerr.New(http.StatusGatewayTimeout, "Callback timed out"))
+}
+
+// NewAuthMethodsFallback returns fallback for action 'authMethods' which responds with given status
+// (for example authmethodsresponse.StatusNotExists).
+func NewAuthMethodsFallback(status authmethodsresponse.Status) Fallback {
+	return func(ctx context.Context, _ any) (any, error) {
+		return authmethodsresponse.New(responseid.FromContext(ctx), status)
+	}
+}
+
+// NewPasswordVerifyFallback returns fallback for action 'passwordVerify' which responds with given
+// success (usually false).
+func NewPasswordVerifyFallback(success bool) Fallback {
+	return func(ctx context.Context, _ any) (any, error) {
+		return passwordverifyresponse.New(responseid.FromContext(ctx), success)
+	}
+}
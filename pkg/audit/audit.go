@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Record is the audit record of a single webhook request.
+type Record struct {
+	Timestamp time.Time `json:"timestamp"`
+	RequestID string    `json:"requestID"`
+	ProjectID string    `json:"projectID"`
+	Action    string    `json:"action"`
+
+	// Username is the pseudonymized username (see Pseudonymizer), empty if the request has no username.
+	Username string `json:"username"`
+
+	// Outcome is the outcome of the action (for example "exists" or "success"), "error" for error responses.
+	Outcome    string `json:"outcome"`
+	StatusCode int    `json:"statusCode"`
+
+	// LatencyMs is the duration from the start of the request until the response was ready in milliseconds.
+	LatencyMs  float64 `json:"latencyMs"`
+	ResponseID string  `json:"responseID"`
+
+	// Principal is the name of the authenticated principal (label of the credential for Basic Auth), empty if
+	// authentication failed or is not configured.
+	Principal string `json:"principal"`
+}
+
+// Sink stores audit records. Implementations must be safe for concurrent use.
+type Sink interface {
+	Write(ctx context.Context, record *Record) error
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(ctx context.Context, record *Record) error
+
+var _ Sink = SinkFunc(nil)
+
+// Write calls the function.
+func (f SinkFunc) Write(ctx context.Context, record *Record) error {
+	return f(ctx, record)
+}
+
+// Pseudonymizer replaces the username of given project with a pseudonym, the same username of the same
+// project must always result in the same pseudonym.
+type Pseudonymizer func(projectID string, username string) string
+
+// NewHMACPseudonymizer returns new pseudonymizer which uses the hex encoded HMAC-SHA256 (with given key) of
+// project and username as pseudonym. Keep the key secret, otherwise usernames can be guessed.
+func NewHMACPseudonymizer(key []byte) (Pseudonymizer, error) {
+	if len(key) == 0 {
+		return nil, errors.New("empty parameter key")
+	}
+
+	return func(projectID string, username string) string {
+		if username == "" {
+			return ""
+		}
+
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(projectID))
+		mac.Write([]byte{0})
+		mac.Write([]byte(username))
+
+		return hex.EncodeToString(mac.Sum(nil))
+	}, nil
+}
+
+// Auditor writes audit records of webhook requests to its sink.
+type Auditor struct {
+	sink          Sink
+	pseudonymizer Pseudonymizer
+}
+
+// New returns new auditor which writes records to given sink, usernames are pseudonymized with given
+// pseudonymizer (see NewHMACPseudonymizer()).
+func New(sink Sink, pseudonymizer Pseudonymizer) (*Auditor, error) {
+	if sink == nil {
+		return nil, errors.New("empty parameter sink")
+	}
+
+	if pseudonymizer == nil {
+		return nil, errors.New("empty parameter pseudonymizer")
+	}
+
+	return &Auditor{
+		sink:          sink,
+		pseudonymizer: pseudonymizer,
+	}, nil
+}
+
+// Write pseudonymizes given username and writes given record to the sink.
+func (a *Auditor) Write(ctx context.Context, record *Record, username string) error {
+	record.Username = a.pseudonymizer(record.ProjectID, username)
+
+	return a.sink.Write(ctx, record)
+}
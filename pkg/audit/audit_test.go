@@ -0,0 +1,62 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/audit"
+)
+
+func TestNewHMACPseudonymizer(t *testing.T) {
+	pseudonymizer, err := audit.NewHMACPseudonymizer(nil)
+	assert.ErrorContains(t, err, "empty parameter key")
+	assert.Nil(t, pseudonymizer)
+
+	pseudonymizer, err = audit.NewHMACPseudonymizer([]byte("key"))
+	require.NoError(t, err)
+
+	pseudonym := pseudonymizer("pro-1", "john.doe@example.com")
+	assert.Len(t, pseudonym, 64)
+	assert.NotContains(t, pseudonym, "john")
+	assert.Equal(t, pseudonym, pseudonymizer("pro-1", "john.doe@example.com"))
+	assert.NotEqual(t, pseudonym, pseudonymizer("pro-2", "john.doe@example.com"))
+	assert.Empty(t, pseudonymizer("pro-1", ""))
+
+	other, err := audit.NewHMACPseudonymizer([]byte("other"))
+	require.NoError(t, err)
+	assert.NotEqual(t, pseudonym, other("pro-1", "john.doe@example.com"))
+}
+
+func TestAuditor(t *testing.T) {
+	pseudonymizer := func(projectID string, username string) string {
+		return projectID + ":" + username
+	}
+
+	auditor, err := audit.New(nil, pseudonymizer)
+	assert.ErrorContains(t, err, "empty parameter sink")
+	assert.Nil(t, auditor)
+
+	var written []*audit.Record
+	sink := audit.SinkFunc(func(_ context.Context, record *audit.Record) error {
+		written = append(written, record)
+
+		return nil
+	})
+
+	auditor, err = audit.New(sink, nil)
+	assert.ErrorContains(t, err, "empty parameter pseudonymizer")
+	assert.Nil(t, auditor)
+
+	auditor, err = audit.New(sink, pseudonymizer)
+	require.NoError(t, err)
+
+	err = auditor.Write(context.Background(), &audit.Record{ProjectID: "pro-1", Outcome: "exists"}, "test")
+	require.NoError(t, err)
+
+	require.Len(t, written, 1)
+	assert.Equal(t, "pro-1:test", written[0].Username)
+	assert.Equal(t, "exists", written[0].Outcome)
+}
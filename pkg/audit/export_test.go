@@ -0,0 +1,9 @@
+package audit
+
+// Dirty exposes to tests whether records were written since the last flush.
+func (s *FileSink) Dirty() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.dirty
+}
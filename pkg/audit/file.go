@@ -0,0 +1,318 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SyncPolicy defines when the file sink flushes records to disk (fsync).
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes after every record, this is the default. Writes are serialized, so the flush delays
+	// every request which is audited at the same time, use SyncInterval for high request rates.
+	SyncAlways SyncPolicy = iota
+
+	// SyncInterval flushes every FileSinkConfig.SyncInterval in the background (records written since the
+	// last flush, up to one interval, can be lost on a crash of the machine). A failed flush is returned by
+	// the next write.
+	SyncInterval
+
+	// SyncNever leaves flushing to the operating system (records which were not flushed yet can be lost on a
+	// crash of the machine).
+	SyncNever
+)
+
+// FileSinkConfig configures the file sink.
+type FileSinkConfig struct {
+	// MaxSize is the size in bytes after which the file is rotated (zero disables size-based rotation).
+	MaxSize int64
+
+	// MaxAge is the duration after which the file is rotated (zero disables time-based rotation).
+	MaxAge time.Duration
+
+	Sync SyncPolicy
+
+	// SyncInterval is required for SyncInterval.
+	SyncInterval time.Duration
+}
+
+// FileSink appends records as JSON lines to a file. Rotated files are renamed to the path suffixed with the
+// time of the rotation (for example audit.jsonl.20240101T120000.000000000Z).
+type FileSink struct {
+	path   string
+	config FileSinkConfig
+
+	mutex     sync.Mutex
+	file      *os.File
+	closed    bool
+	size      int64
+	createdAt time.Time
+	dirty     bool
+	syncErr   error
+	done      chan struct{}
+	stopped   chan struct{}
+}
+
+var _ Sink = &FileSink{}
+
+// NewFileSink returns new file sink which appends to the file at given path (nil config for defaults).
+func NewFileSink(path string, config *FileSinkConfig) (*FileSink, error) {
+	if path == "" {
+		return nil, errors.New("empty parameter path")
+	}
+
+	if config == nil {
+		config = &FileSinkConfig{}
+	}
+
+	if config.MaxSize < 0 || config.MaxAge < 0 {
+		return nil, errors.New("invalid parameter config, max size and max age must not be negative")
+	}
+
+	if config.Sync != SyncAlways && config.Sync != SyncInterval && config.Sync != SyncNever {
+		return nil, errors.Errorf("invalid parameter config.Sync (%d)", config.Sync)
+	}
+
+	if config.Sync == SyncInterval && config.SyncInterval <= 0 {
+		return nil, errors.New("invalid parameter config.SyncInterval, must be greater than zero for SyncInterval")
+	}
+
+	s := &FileSink{
+		path:   path,
+		config: *config,
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	if s.config.Sync == SyncInterval {
+		s.done = make(chan struct{})
+		s.stopped = make(chan struct{})
+
+		go s.syncPeriodically()
+	}
+
+	return s, nil
+}
+
+// Write appends given record to the file, the file is rotated before if necessary.
+func (s *FileSink) Write(_ context.Context, record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	line = append(line, '\n')
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return errors.New("file sink is closed")
+	}
+
+	if err := s.syncErr; err != nil {
+		s.syncErr = nil
+
+		return errors.WithMessage(err, "background flush failed")
+	}
+
+	// the file is reopened if a rotation failed
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+
+	var rotateErr error
+	if s.rotationRequired(int64(len(line))) {
+		// the record is still written if the current file could be reopened after a failed rotation
+		if rotateErr = s.rotate(); s.file == nil {
+			return rotateErr
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := s.sync(false); err != nil {
+		return err
+	}
+
+	if rotateErr != nil {
+		return errors.WithMessage(rotateErr, "rotation failed (record was written)")
+	}
+
+	return nil
+}
+
+// Close flushes and closes the file (and stops flushing in the background).
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+
+	if s.closed {
+		s.mutex.Unlock()
+
+		return nil
+	}
+
+	s.closed = true
+
+	var err error
+	if s.file != nil {
+		err = s.close()
+		s.file = nil
+	}
+
+	s.mutex.Unlock()
+
+	if s.done != nil {
+		close(s.done)
+		<-s.stopped
+	}
+
+	return err
+}
+
+// syncPeriodically flushes the file every sync interval if records were written since the last flush, until
+// the sink is closed.
+func (s *FileSink) syncPeriodically() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.mutex.Lock()
+			if !s.closed && s.file != nil && s.dirty {
+				s.syncErr = s.sync(true)
+			}
+			s.mutex.Unlock()
+		}
+	}
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return errors.WithStack(err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+	s.createdAt = createdAt(s.path, info)
+	s.dirty = false
+
+	return nil
+}
+
+// createdAt returns the creation time of given (existing) file, so the age of the file counts from its
+// creation after a restart too. It is the timestamp of the first record as the creation time of files is not
+// available on all platforms, the time of the last modification if the first record cannot be read.
+func createdAt(path string, info os.FileInfo) time.Time {
+	if info.Size() == 0 {
+		return time.Now()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return info.ModTime()
+	}
+
+	defer file.Close()
+
+	first := &Record{}
+	if err := json.NewDecoder(file).Decode(first); err != nil || first.Timestamp.IsZero() {
+		return info.ModTime()
+	}
+
+	return first.Timestamp
+}
+
+// rotationRequired returns if the file must be rotated before a record with given length is written.
+func (s *FileSink) rotationRequired(length int64) bool {
+	if s.size == 0 {
+		return false
+	}
+
+	if s.config.MaxSize > 0 && s.size+length > s.config.MaxSize {
+		return true
+	}
+
+	return s.config.MaxAge > 0 && time.Since(s.createdAt) >= s.config.MaxAge
+}
+
+// rotate renames the file and opens a new one. If renaming fails the current file is reopened (to keep
+// appending to it), if that fails too the file is reopened by the next write.
+func (s *FileSink) rotate() error {
+	err := s.close()
+	s.file = nil
+
+	if err != nil {
+		return err
+	}
+
+	rotatedPath := s.path + "." + time.Now().UTC().Format("20060102T150405.000000000Z")
+	if err := os.Rename(s.path, rotatedPath); err != nil {
+		_ = s.open()
+
+		return errors.WithStack(err)
+	}
+
+	return s.open()
+}
+
+func (s *FileSink) close() error {
+	if err := s.sync(true); err != nil {
+		_ = s.file.Close()
+
+		return err
+	}
+
+	return errors.WithStack(s.file.Close())
+}
+
+// sync flushes the file according to the sync policy, force flushes regardless of the policy (except
+// SyncNever). With SyncInterval the file is only marked dirty, it is flushed in the background.
+func (s *FileSink) sync(force bool) error {
+	switch s.config.Sync {
+	case SyncNever:
+		return nil
+	case SyncInterval:
+		if !force {
+			s.dirty = true
+
+			return nil
+		}
+	}
+
+	if err := s.file.Sync(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	s.dirty = false
+
+	return nil
+}
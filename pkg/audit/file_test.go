@@ -0,0 +1,265 @@
+package audit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/audit"
+)
+
+func readRecords(t *testing.T, path string) []*audit.Record {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	var records []*audit.Record
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := &audit.Record{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), record))
+
+		records = append(records, record)
+	}
+
+	require.NoError(t, scanner.Err())
+
+	return records
+}
+
+func rotatedFiles(t *testing.T, path string) []string {
+	matches, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+
+	return matches
+}
+
+func TestNewFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	tests := []struct {
+		name    string
+		path    string
+		config  *audit.FileSinkConfig
+		wantErr string
+	}{
+		{
+			name:    "empty path",
+			wantErr: "empty parameter path",
+		},
+		{
+			name:    "negative max size",
+			path:    path,
+			config:  &audit.FileSinkConfig{MaxSize: -1},
+			wantErr: "must not be negative",
+		},
+		{
+			name:    "invalid sync policy",
+			path:    path,
+			config:  &audit.FileSinkConfig{Sync: 42},
+			wantErr: "invalid parameter config.Sync (42)",
+		},
+		{
+			name:    "missing sync interval",
+			path:    path,
+			config:  &audit.FileSinkConfig{Sync: audit.SyncInterval},
+			wantErr: "invalid parameter config.SyncInterval",
+		},
+		{
+			name:    "missing directory",
+			path:    filepath.Join(t.TempDir(), "missing", "audit.jsonl"),
+			wantErr: "no such file or directory",
+		},
+		{
+			name: "defaults",
+			path: path,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sink, err := audit.NewFileSink(test.path, test.config)
+			if test.wantErr != "" {
+				assert.ErrorContains(t, err, test.wantErr)
+				assert.Nil(t, sink)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NoError(t, sink.Close())
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for _, policy := range []audit.SyncPolicy{audit.SyncAlways, audit.SyncInterval, audit.SyncNever} {
+		sink, err := audit.NewFileSink(path, &audit.FileSinkConfig{Sync: policy, SyncInterval: time.Second})
+		require.NoError(t, err)
+
+		require.NoError(t, sink.Write(ctx, &audit.Record{RequestID: "who-1", Outcome: "exists", LatencyMs: 1.5}))
+		require.NoError(t, sink.Close())
+		require.NoError(t, sink.Close())
+
+		assert.ErrorContains(t, sink.Write(ctx, &audit.Record{}), "file sink is closed")
+	}
+
+	// existing file is appended to
+	records := readRecords(t, path)
+	require.Len(t, records, 3)
+	assert.Equal(t, "who-1", records[2].RequestID)
+	assert.Equal(t, "exists", records[2].Outcome)
+	assert.Equal(t, 1.5, records[2].LatencyMs)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	assert.Empty(t, rotatedFiles(t, path))
+}
+
+func TestFileSinkSyncInterval(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	sink, err := audit.NewFileSink(path, &audit.FileSinkConfig{Sync: audit.SyncInterval, SyncInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(ctx, &audit.Record{RequestID: "who-1"}))
+	assert.True(t, sink.Dirty())
+
+	// flushed in the background without further writes
+	assert.Eventually(t, func() bool {
+		return !sink.Dirty()
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, sink.Write(ctx, &audit.Record{RequestID: "who-2"}))
+	require.NoError(t, sink.Close())
+	assert.False(t, sink.Dirty())
+
+	assert.Len(t, readRecords(t, path), 2)
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+		line, err := json.Marshal(&audit.Record{RequestID: "who-1"})
+		require.NoError(t, err)
+
+		sink, err := audit.NewFileSink(path, &audit.FileSinkConfig{MaxSize: int64(len(line)+1) * 2})
+		require.NoError(t, err)
+
+		defer sink.Close()
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, sink.Write(ctx, &audit.Record{RequestID: "who-1"}))
+		}
+
+		assert.Len(t, readRecords(t, path), 1)
+
+		rotated := rotatedFiles(t, path)
+		require.Len(t, rotated, 1)
+		assert.Len(t, readRecords(t, rotated[0]), 2)
+	})
+
+	t.Run("time", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+		sink, err := audit.NewFileSink(path, &audit.FileSinkConfig{MaxAge: 20 * time.Millisecond})
+		require.NoError(t, err)
+
+		defer sink.Close()
+
+		require.NoError(t, sink.Write(ctx, &audit.Record{RequestID: "who-1"}))
+		require.NoError(t, sink.Write(ctx, &audit.Record{RequestID: "who-2"}))
+
+		time.Sleep(30 * time.Millisecond)
+
+		require.NoError(t, sink.Write(ctx, &audit.Record{RequestID: "who-3"}))
+
+		records := readRecords(t, path)
+		require.Len(t, records, 1)
+		assert.Equal(t, "who-3", records[0].RequestID)
+
+		rotated := rotatedFiles(t, path)
+		require.Len(t, rotated, 1)
+		assert.Len(t, readRecords(t, rotated[0]), 2)
+	})
+}
+
+func TestFileSinkRotationFailure(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "audit")
+	path := filepath.Join(dir, "audit.jsonl")
+
+	require.NoError(t, os.Mkdir(dir, 0o700))
+
+	sink, err := audit.NewFileSink(path, &audit.FileSinkConfig{MaxSize: 1})
+	require.NoError(t, err)
+
+	defer sink.Close()
+
+	require.NoError(t, sink.Write(ctx, &audit.Record{RequestID: "who-1"}))
+
+	// rotation (and reopening) fails while the directory is missing
+	require.NoError(t, os.RemoveAll(dir))
+	assert.Error(t, sink.Write(ctx, &audit.Record{RequestID: "who-2"}))
+
+	// the file is reopened by the next write
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	require.NoError(t, sink.Write(ctx, &audit.Record{RequestID: "who-3"}))
+
+	records := readRecords(t, path)
+	require.Len(t, records, 1)
+	assert.Equal(t, "who-3", records[0].RequestID)
+}
+
+func TestFileSinkAgeAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	sink, err := audit.NewFileSink(path, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(ctx, &audit.Record{Timestamp: time.Now().Add(-2 * time.Hour), RequestID: "who-1"}))
+	require.NoError(t, sink.Close())
+
+	// the age counts from the first record, not from reopening the file
+	sink, err = audit.NewFileSink(path, &audit.FileSinkConfig{MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(ctx, &audit.Record{Timestamp: time.Now(), RequestID: "who-2"}))
+	require.NoError(t, sink.Close())
+
+	require.Len(t, rotatedFiles(t, path), 1)
+	assert.Len(t, readRecords(t, path), 1)
+
+	// the time of the last modification is used if the first record cannot be read
+	path = filepath.Join(t.TempDir(), "audit.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("invalid\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now().Add(-2*time.Hour), time.Now().Add(-2*time.Hour)))
+
+	sink, err = audit.NewFileSink(path, &audit.FileSinkConfig{MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(ctx, &audit.Record{Timestamp: time.Now(), RequestID: "who-3"}))
+	require.NoError(t, sink.Close())
+
+	require.Len(t, rotatedFiles(t, path), 1)
+	assert.Len(t, readRecords(t, path), 1)
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnauthorized is returned (wrapped) by authenticators if the request could not be authenticated. Other
+// errors (a failing token store for example) result in an internal server error.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Request contains the parts of a webhook request which are available for authentication (the body is not
+// read before authentication, use signatures to authenticate the body).
+type Request struct {
+	Method string
+	URL    string
+	Header http.Header
+
+	// ClientIP is the address of the client (see ipfilter.Filter.ClientIP()).
+	ClientIP netip.Addr
+}
+
+// Principal is the authenticated caller.
+type Principal struct {
+	// Scheme is the authentication scheme (for example "basic" or "bearer").
+	Scheme string
+
+	// Name identifies the caller within the scheme (for example the label of the matched credential).
+	Name string
+}
+
+// Authenticator authenticates webhook requests. Implementations must be safe for concurrent use.
+type Authenticator interface {
+	// Authenticate returns the principal of given request or an error wrapping ErrUnauthorized if the
+	// request could not be authenticated.
+	Authenticate(ctx context.Context, req *Request) (*Principal, error)
+}
+
+// Challenger is implemented by authenticators which send a WWW-Authenticate challenge with 401 responses.
+type Challenger interface {
+	// Challenge returns the value of the WWW-Authenticate header.
+	Challenge() string
+}
+
+// Func adapts a function to the Authenticator interface (for custom schemes).
+type Func func(ctx context.Context, req *Request) (*Principal, error)
+
+var _ Authenticator = Func(nil)
+
+// Authenticate calls the function.
+func (f Func) Authenticate(ctx context.Context, req *Request) (*Principal, error) {
+	return f(ctx, req)
+}
+
+// Unauthorized returns new error wrapping ErrUnauthorized with given reason (only logged).
+func Unauthorized(reason string) error {
+	return errors.Wrap(ErrUnauthorized, reason)
+}
+
+// Challenge returns the WWW-Authenticate challenge of given authenticator (empty if it has none).
+func Challenge(authenticator Authenticator) string {
+	if challenger, ok := authenticator.(Challenger); ok {
+		return challenger.Challenge()
+	}
+
+	return ""
+}
+
+// joinChallenges joins the challenges of given authenticators (multiple challenges are allowed in one
+// WWW-Authenticate header).
+func joinChallenges(authenticators []Authenticator) string {
+	challenges := make([]string, 0, len(authenticators))
+	for _, authenticator := range authenticators {
+		if challenge := Challenge(authenticator); challenge != "" {
+			challenges = append(challenges, challenge)
+		}
+	}
+
+	return strings.Join(challenges, ", ")
+}
@@ -0,0 +1,145 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/auth"
+	"github.com/corbado/webhook-go/pkg/credential"
+)
+
+func newRequest(setup func(r *http.Request)) *auth.Request {
+	r, _ := http.NewRequest(http.MethodPost, "/webhook", nil)
+	setup(r)
+
+	return &auth.Request{Method: r.Method, URL: r.URL.String(), Header: r.Header}
+}
+
+func basicAuth(username string, password string) func(r *http.Request) {
+	return func(r *http.Request) {
+		r.SetBasicAuth(username, password)
+	}
+}
+
+func bearer(token string) func(r *http.Request) {
+	return func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+func newBasic(t *testing.T) *auth.Basic {
+	cred, err := credential.New("current", "webhookUsername", "webhookPassword", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	basic, err := auth.NewBasic([]*credential.Credential{cred})
+	require.NoError(t, err)
+
+	return basic
+}
+
+func newBearer(t *testing.T) *auth.Bearer {
+	b, err := auth.NewBearer(map[string]string{"ingress": "secretToken"})
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestBasic(t *testing.T) {
+	_, err := auth.NewBasic(nil)
+	assert.ErrorContains(t, err, "empty parameter credentials")
+
+	basic := newBasic(t)
+
+	principal, err := basic.Authenticate(context.Background(), newRequest(basicAuth("webhookUsername", "webhookPassword")))
+	require.NoError(t, err)
+	assert.Equal(t, &auth.Principal{Scheme: auth.SchemeBasic, Name: "current"}, principal)
+
+	_, err = basic.Authenticate(context.Background(), newRequest(basicAuth("webhookUsername", "wrong")))
+	assert.ErrorIs(t, err, auth.ErrUnauthorized)
+
+	_, err = basic.Authenticate(context.Background(), newRequest(bearer("secretToken")))
+	assert.ErrorIs(t, err, auth.ErrUnauthorized)
+
+	assert.Equal(t, `Basic realm="restricted", charset="UTF-8"`, auth.Challenge(basic))
+}
+
+func TestBearer(t *testing.T) {
+	_, err := auth.NewBearer(map[string]string{"ingress": ""})
+	assert.ErrorContains(t, err, "empty token for label 'ingress'")
+
+	_, err = auth.NewBearer(map[string]string{"": "secretToken"})
+	assert.ErrorContains(t, err, "empty label in parameter tokens")
+
+	b := newBearer(t)
+
+	principal, err := b.Authenticate(context.Background(), newRequest(bearer("secretToken")))
+	require.NoError(t, err)
+	assert.Equal(t, &auth.Principal{Scheme: auth.SchemeBearer, Name: "ingress"}, principal)
+
+	_, err = b.Authenticate(context.Background(), newRequest(bearer("wrong")))
+	assert.ErrorIs(t, err, auth.ErrUnauthorized)
+
+	_, err = b.Authenticate(context.Background(), newRequest(basicAuth("webhookUsername", "webhookPassword")))
+	assert.ErrorIs(t, err, auth.ErrUnauthorized)
+}
+
+func TestCompose(t *testing.T) {
+	custom := auth.Func(func(_ context.Context, req *auth.Request) (*auth.Principal, error) {
+		if req.Header.Get("X-Ingress") != "trusted" {
+			return nil, auth.Unauthorized("X-Ingress header missing")
+		}
+
+		return &auth.Principal{Scheme: "ingress", Name: "trusted"}, nil
+	})
+
+	_, err := auth.All()
+	assert.ErrorContains(t, err, "empty parameter authenticators")
+
+	_, err = auth.Any(custom, nil)
+	assert.ErrorContains(t, err, "empty authenticator in parameter authenticators")
+
+	all, err := auth.All(newBasic(t), custom)
+	require.NoError(t, err)
+
+	principal, err := all.Authenticate(context.Background(), newRequest(func(r *http.Request) {
+		r.SetBasicAuth("webhookUsername", "webhookPassword")
+		r.Header.Set("X-Ingress", "trusted")
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "current", principal.Name)
+
+	_, err = all.Authenticate(context.Background(), newRequest(basicAuth("webhookUsername", "webhookPassword")))
+	assert.ErrorIs(t, err, auth.ErrUnauthorized)
+
+	anyOf, err := auth.Any(newBasic(t), newBearer(t))
+	require.NoError(t, err)
+
+	principal, err = anyOf.Authenticate(context.Background(), newRequest(bearer("secretToken")))
+	require.NoError(t, err)
+	assert.Equal(t, auth.SchemeBearer, principal.Scheme)
+
+	principal, err = anyOf.Authenticate(context.Background(), newRequest(basicAuth("webhookUsername", "webhookPassword")))
+	require.NoError(t, err)
+	assert.Equal(t, auth.SchemeBasic, principal.Scheme)
+
+	_, err = anyOf.Authenticate(context.Background(), newRequest(bearer("wrong")))
+	assert.ErrorIs(t, err, auth.ErrUnauthorized)
+
+	assert.Equal(t, `Basic realm="restricted", charset="UTF-8", Bearer realm="restricted"`, auth.Challenge(anyOf))
+
+	// errors other than ErrUnauthorized are returned if no authenticator succeeded
+	storeErr := errors.New("token store unavailable")
+	anyOf, err = auth.Any(auth.Func(func(_ context.Context, _ *auth.Request) (*auth.Principal, error) {
+		return nil, storeErr
+	}), newBearer(t))
+	require.NoError(t, err)
+
+	_, err = anyOf.Authenticate(context.Background(), newRequest(bearer("wrong")))
+	assert.ErrorIs(t, err, storeErr)
+}
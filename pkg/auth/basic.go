@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/corbado/webhook-go/pkg/credential"
+)
+
+// SchemeBasic is the scheme of principals authenticated by Basic.
+const SchemeBasic = "basic"
+
+// Basic authenticates requests with Basic Auth (this is the default authenticator of the webhook).
+type Basic struct {
+	credentials []*credential.Credential
+}
+
+var _ Authenticator = &Basic{}
+var _ Challenger = &Basic{}
+
+// NewBasic returns new Basic Auth authenticator which accepts given credentials (see credential.Match()).
+func NewBasic(credentials []*credential.Credential) (*Basic, error) {
+	if len(credentials) == 0 {
+		return nil, errors.New("empty parameter credentials")
+	}
+
+	for _, cred := range credentials {
+		if cred == nil {
+			return nil, errors.New("empty credential in parameter credentials")
+		}
+	}
+
+	return &Basic{credentials: credentials}, nil
+}
+
+// Authenticate authenticates given request with Basic Auth, the name of the principal is the label of the
+// matched credential.
+func (b *Basic) Authenticate(_ context.Context, req *Request) (*Principal, error) {
+	username, password, ok := (&http.Request{Header: req.Header}).BasicAuth()
+	if !ok {
+		return nil, Unauthorized("Basic Auth header missing or malformed")
+	}
+
+	matched, ok := credential.Match(b.credentials, username, password, time.Now())
+	if !ok {
+		return nil, Unauthorized("no matching credential")
+	}
+
+	return &Principal{Scheme: SchemeBasic, Name: matched.Label()}, nil
+}
+
+// Challenge returns the Basic Auth challenge.
+func (b *Basic) Challenge() string {
+	return `Basic realm="restricted", charset="UTF-8"`
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SchemeBearer is the scheme of principals authenticated by Bearer.
+const SchemeBearer = "bearer"
+
+// Bearer authenticates requests with static bearer tokens (Authorization: Bearer <token>).
+type Bearer struct {
+	tokens []bearerToken
+}
+
+var _ Authenticator = &Bearer{}
+var _ Challenger = &Bearer{}
+
+type bearerToken struct {
+	label string
+	hash  [sha256.Size]byte
+}
+
+// NewBearer returns new bearer token authenticator, tokens maps labels (name of the principal) to accepted
+// tokens. Tokens are compared in constant time.
+func NewBearer(tokens map[string]string) (*Bearer, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("empty parameter tokens")
+	}
+
+	b := &Bearer{tokens: make([]bearerToken, 0, len(tokens))}
+	for label, token := range tokens {
+		if label == "" {
+			return nil, errors.New("empty label in parameter tokens")
+		}
+
+		if token == "" {
+			return nil, errors.Errorf("empty token for label '%s' in parameter tokens", label)
+		}
+
+		b.tokens = append(b.tokens, bearerToken{label: label, hash: sha256.Sum256([]byte(token))})
+	}
+
+	return b, nil
+}
+
+// Authenticate authenticates given request with its bearer token, the name of the principal is the label
+// of the matched token.
+func (b *Bearer) Authenticate(_ context.Context, req *Request) (*Principal, error) {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, Unauthorized("bearer token missing or malformed")
+	}
+
+	hash := sha256.Sum256([]byte(token))
+
+	// all tokens are compared to not leak which token matched through timing
+	label := ""
+	for i := range b.tokens {
+		if subtle.ConstantTimeCompare(hash[:], b.tokens[i].hash[:]) == 1 && label == "" {
+			label = b.tokens[i].label
+		}
+	}
+
+	if label == "" {
+		return nil, Unauthorized("no matching bearer token")
+	}
+
+	return &Principal{Scheme: SchemeBearer, Name: label}, nil
+}
+
+// Challenge returns the bearer challenge.
+func (b *Bearer) Challenge() string {
+	return `Bearer realm="restricted"`
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// AllOf requires all of its authenticators to succeed.
+type AllOf struct {
+	authenticators []Authenticator
+}
+
+var _ Authenticator = &AllOf{}
+var _ Challenger = &AllOf{}
+
+// All returns new authenticator which requires all given authenticators to succeed (AND), the principal of
+// the first one is returned.
+func All(authenticators ...Authenticator) (*AllOf, error) {
+	if err := assertAuthenticators(authenticators); err != nil {
+		return nil, err
+	}
+
+	return &AllOf{authenticators: authenticators}, nil
+}
+
+// Authenticate calls all authenticators in order and stops at the first failure.
+func (a *AllOf) Authenticate(ctx context.Context, req *Request) (*Principal, error) {
+	var principal *Principal
+
+	for _, authenticator := range a.authenticators {
+		p, err := authenticator.Authenticate(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if principal == nil {
+			principal = p
+		}
+	}
+
+	return principal, nil
+}
+
+// Challenge returns the challenges of all authenticators.
+func (a *AllOf) Challenge() string {
+	return joinChallenges(a.authenticators)
+}
+
+// AnyOf requires one of its authenticators to succeed.
+type AnyOf struct {
+	authenticators []Authenticator
+}
+
+var _ Authenticator = &AnyOf{}
+var _ Challenger = &AnyOf{}
+
+// Any returns new authenticator which requires one of given authenticators to succeed (OR), the principal
+// of the first successful one is returned.
+func Any(authenticators ...Authenticator) (*AnyOf, error) {
+	if err := assertAuthenticators(authenticators); err != nil {
+		return nil, err
+	}
+
+	return &AnyOf{authenticators: authenticators}, nil
+}
+
+// Authenticate calls the authenticators in order until one succeeds. Errors other than ErrUnauthorized are
+// only returned if no authenticator succeeded.
+func (a *AnyOf) Authenticate(ctx context.Context, req *Request) (*Principal, error) {
+	var lastErr error
+
+	for _, authenticator := range a.authenticators {
+		principal, err := authenticator.Authenticate(ctx, req)
+		if err == nil {
+			return principal, nil
+		}
+
+		if lastErr == nil || !errors.Is(err, ErrUnauthorized) {
+			lastErr = err
+		}
+	}
+
+	return nil, lastErr
+}
+
+// Challenge returns the challenges of all authenticators.
+func (a *AnyOf) Challenge() string {
+	return joinChallenges(a.authenticators)
+}
+
+func assertAuthenticators(authenticators []Authenticator) error {
+	if len(authenticators) == 0 {
+		return errors.New("empty parameter authenticators")
+	}
+
+	for _, authenticator := range authenticators {
+		if authenticator == nil {
+			return errors.New("empty authenticator in parameter authenticators")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package bruteforce
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/corbado/webhook-go/pkg/callback"
+	"github.com/corbado/webhook-go/pkg/dto/passwordverifyrequest"
+)
+
+const (
+	// DefaultMaxFailures is the number of failed attempts within the window after which a username is locked
+	// if none is configured.
+	DefaultMaxFailures = 10
+
+	// DefaultWindow is the duration of the sliding window in which failed attempts are counted if none is
+	// configured.
+	DefaultWindow = 15 * time.Minute
+
+	// DefaultDelayStep is the delay added per failed attempt within the window if none is configured.
+	DefaultDelayStep = 100 * time.Millisecond
+
+	// DefaultMaxDelay is the maximum delay if none is configured.
+	DefaultMaxDelay = 2 * time.Second
+)
+
+// Store stores the failed attempts of keys (projectID and normalized username). Implementations must be safe
+// for concurrent use, implementations backed by a shared database make the limiter work across replicas.
+type Store interface {
+	// Failures returns the number of failures of given key within the sliding window ending at now.
+	Failures(ctx context.Context, key string, now time.Time, window time.Duration) (int, error)
+
+	// Reserve atomically records an attempt of given key at now as failure if there are fewer than max
+	// failures within the sliding window ending at now. It returns the number of failures within the window
+	// before the attempt and whether the attempt was recorded.
+	Reserve(ctx context.Context, key string, now time.Time, window time.Duration, max int) (int, bool, error)
+
+	// Release removes the attempt of given key recorded at given time by Reserve (for attempts which did not
+	// fail, for example because the callback returned an error).
+	Release(ctx context.Context, key string, at time.Time) error
+
+	// Reset removes all failures of given key.
+	Reset(ctx context.Context, key string) error
+}
+
+// Config configures the limiter, zero values are replaced by their defaults.
+type Config struct {
+	// MaxFailures is the number of failed attempts within the window after which a username is locked.
+	MaxFailures int
+
+	// Window is the duration of the sliding window in which failed attempts are counted.
+	Window time.Duration
+
+	// DelayStep is the delay added per failed attempt within the window (progressive delay), use a negative
+	// value to disable delays.
+	DelayStep time.Duration
+
+	// MaxDelay is the maximum delay.
+	MaxDelay time.Duration
+}
+
+// Limiter limits failed passwordVerify attempts per project and username.
+type Limiter struct {
+	store       Store
+	maxFailures int
+	window      time.Duration
+	delayStep   time.Duration
+	maxDelay    time.Duration
+}
+
+// New returns new limiter with given store and config (nil config for defaults).
+func New(store Store, config *Config) (*Limiter, error) {
+	if store == nil {
+		return nil, errors.New("empty parameter store")
+	}
+
+	if config == nil {
+		config = &Config{}
+	}
+
+	if config.MaxFailures < 0 {
+		return nil, errors.Errorf("invalid parameter config.MaxFailures (%d)", config.MaxFailures)
+	}
+
+	if config.Window < 0 || config.MaxDelay < 0 {
+		return nil, errors.New("invalid parameter config, window and max delay must not be negative")
+	}
+
+	l := &Limiter{
+		store:       store,
+		maxFailures: config.MaxFailures,
+		window:      config.Window,
+		delayStep:   config.DelayStep,
+		maxDelay:    config.MaxDelay,
+	}
+
+	if l.maxFailures == 0 {
+		l.maxFailures = DefaultMaxFailures
+	}
+
+	if l.window == 0 {
+		l.window = DefaultWindow
+	}
+
+	if l.delayStep == 0 {
+		l.delayStep = DefaultDelayStep
+	}
+
+	if l.maxDelay == 0 {
+		l.maxDelay = DefaultMaxDelay
+	}
+
+	return l, nil
+}
+
+// Wrap returns given passwordVerify callback wrapped by the limiter. Every attempt is reserved as failure in
+// the store before the callback is called (so parallel attempts cannot pass the limit) and forgotten again if
+// the callback succeeds or returns an error. Locked usernames get success=false without calling the callback,
+// all attempts are delayed progressively by previous failures (locked ones too, so the response time does not
+// reveal the lock).
+func (l *Limiter) Wrap(passwordVerifyCallback callback.PasswordVerifyWithResult) callback.PasswordVerifyWithResult {
+	if passwordVerifyCallback == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, req *passwordverifyrequest.DTO) (*callback.PasswordVerifyResult, error) {
+		key := Key(req.ProjectID, req.Data.Username)
+		now := time.Now()
+
+		failures, reserved, err := l.store.Reserve(ctx, key, now, l.window, l.maxFailures)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := sleep(ctx, l.delay(failures)); err != nil {
+			if reserved {
+				l.release(ctx, key, now)
+			}
+
+			return nil, err
+		}
+
+		if !reserved {
+			return &callback.PasswordVerifyResult{Success: false}, nil
+		}
+
+		result, err := passwordVerifyCallback(ctx, req)
+		if err != nil {
+			l.release(ctx, key, now)
+
+			return nil, err
+		}
+
+		if result == nil {
+			l.release(ctx, key, now)
+
+			return nil, errors.New("passwordVerifyCallback returned empty result")
+		}
+
+		if result.Success {
+			if err := l.store.Reset(ctx, key); err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+	}
+}
+
+// release removes the reserved attempt, errors are ignored as the attempt only counts as failure then (the
+// error of the attempt itself is returned).
+func (l *Limiter) release(ctx context.Context, key string, at time.Time) {
+	_ = l.store.Release(context.WithoutCancel(ctx), key, at)
+}
+
+// Locked returns if given username of given project is currently locked.
+func (l *Limiter) Locked(ctx context.Context, projectID string, username string) (bool, error) {
+	failures, err := l.store.Failures(ctx, Key(projectID, username), time.Now(), l.window)
+	if err != nil {
+		return false, err
+	}
+
+	return failures >= l.maxFailures, nil
+}
+
+// Unlock forgets all failed attempts of given username of given project (for support staff to unlock users).
+func (l *Limiter) Unlock(ctx context.Context, projectID string, username string) error {
+	return l.store.Reset(ctx, Key(projectID, username))
+}
+
+// Key returns the store key of given project and username, the username is normalized (Unicode NFKC,
+// surrounding whitespace removed, lower case) so variants of the same username share their failures.
+func Key(projectID string, username string) string {
+	return projectID + "/" + strings.ToLower(strings.TrimSpace(norm.NFKC.String(username)))
+}
+
+// delay returns the progressive delay for given number of previous failures.
+func (l *Limiter) delay(failures int) time.Duration {
+	if l.delayStep < 0 || failures == 0 {
+		return 0
+	}
+
+	delay := time.Duration(failures) * l.delayStep
+	if delay > l.maxDelay {
+		return l.maxDelay
+	}
+
+	return delay
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	}
+}
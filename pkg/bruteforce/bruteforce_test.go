@@ -0,0 +1,236 @@
+package bruteforce_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/bruteforce"
+	"github.com/corbado/webhook-go/pkg/callback"
+	"github.com/corbado/webhook-go/pkg/dto/passwordverifyrequest"
+	"github.com/corbado/webhook-go/pkg/redact"
+)
+
+func newRequest(projectID string, username string, password string) *passwordverifyrequest.DTO {
+	return &passwordverifyrequest.DTO{
+		ID:        "pwv-1",
+		ProjectID: projectID,
+		Action:    "passwordVerify",
+		Data: &passwordverifyrequest.DTOData{
+			Username: username,
+			Password: redact.Secret(password),
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	l, err := bruteforce.New(nil, nil)
+	assert.ErrorContains(t, err, "empty parameter store")
+	assert.Nil(t, l)
+
+	l, err = bruteforce.New(bruteforce.NewMemoryStore(), &bruteforce.Config{MaxFailures: -1})
+	assert.ErrorContains(t, err, "invalid parameter config.MaxFailures")
+	assert.Nil(t, l)
+
+	l, err = bruteforce.New(bruteforce.NewMemoryStore(), nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, l)
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "pro-1/jane@example.com", bruteforce.Key("pro-1", " Jane@Example.com "))
+	assert.Equal(t, bruteforce.Key("pro-1", "jane"), bruteforce.Key("pro-1", "ｊａｎｅ"))
+	assert.NotEqual(t, bruteforce.Key("pro-1", "jane"), bruteforce.Key("pro-2", "jane"))
+}
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+
+	l, err := bruteforce.New(bruteforce.NewMemoryStore(), &bruteforce.Config{MaxFailures: 3, DelayStep: time.Millisecond})
+	require.NoError(t, err)
+
+	assert.Nil(t, l.Wrap(nil))
+
+	calls := 0
+	passwordVerifyCallback := l.Wrap(func(_ context.Context, req *passwordverifyrequest.DTO) (*callback.PasswordVerifyResult, error) {
+		calls++
+
+		return &callback.PasswordVerifyResult{Success: req.Data.Password == "correct"}, nil
+	})
+
+	// success resets failures
+	for _, password := range []string{"wrong", "wrong", "correct", "wrong", "wrong"} {
+		result, err := passwordVerifyCallback(ctx, newRequest("pro-1", "jane", password))
+		require.NoError(t, err)
+		assert.Equal(t, password == "correct", result.Success)
+	}
+
+	locked, err := l.Locked(ctx, "pro-1", "jane")
+	require.NoError(t, err)
+	assert.False(t, locked)
+
+	// progressive delay of 2ms for 2 previous failures
+	start := time.Now()
+	result, err := passwordVerifyCallback(ctx, newRequest("pro-1", "Jane", "wrong"))
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.GreaterOrEqual(t, time.Since(start), 2*time.Millisecond)
+
+	locked, err = l.Locked(ctx, "pro-1", "jane")
+	require.NoError(t, err)
+	assert.True(t, locked)
+
+	// locked, callback is not called even for the correct password
+	result, err = passwordVerifyCallback(ctx, newRequest("pro-1", "jane", "correct"))
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, 6, calls)
+
+	// other projects are not affected
+	result, err = passwordVerifyCallback(ctx, newRequest("pro-2", "jane", "correct"))
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+
+	require.NoError(t, l.Unlock(ctx, "pro-1", "JANE"))
+
+	result, err = passwordVerifyCallback(ctx, newRequest("pro-1", "jane", "correct"))
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+
+	// delay honours cancellation
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = passwordVerifyCallback(ctx, newRequest("pro-1", "jane", "wrong"))
+	require.NoError(t, err)
+
+	_, err = passwordVerifyCallback(cancelled, newRequest("pro-1", "jane", "wrong"))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWrapParallel(t *testing.T) {
+	ctx := context.Background()
+
+	l, err := bruteforce.New(bruteforce.NewMemoryStore(), &bruteforce.Config{MaxFailures: 3, DelayStep: -1})
+	require.NoError(t, err)
+
+	var calls atomic.Int32
+	passwordVerifyCallback := l.Wrap(func(_ context.Context, _ *passwordverifyrequest.DTO) (*callback.PasswordVerifyResult, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+
+		return &callback.PasswordVerifyResult{Success: false}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			result, err := passwordVerifyCallback(ctx, newRequest("pro-1", "jane", "wrong"))
+			assert.NoError(t, err)
+			assert.False(t, result.Success)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestWrapLockedDelay(t *testing.T) {
+	ctx := context.Background()
+
+	l, err := bruteforce.New(bruteforce.NewMemoryStore(), &bruteforce.Config{MaxFailures: 1, DelayStep: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	passwordVerifyCallback := l.Wrap(func(_ context.Context, _ *passwordverifyrequest.DTO) (*callback.PasswordVerifyResult, error) {
+		return &callback.PasswordVerifyResult{Success: false}, nil
+	})
+
+	_, err = passwordVerifyCallback(ctx, newRequest("pro-1", "jane", "wrong"))
+	require.NoError(t, err)
+
+	// locked usernames are delayed like other attempts
+	start := time.Now()
+	result, err := passwordVerifyCallback(ctx, newRequest("pro-1", "jane", "wrong"))
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestWrapCallbackFailure(t *testing.T) {
+	ctx := context.Background()
+
+	l, err := bruteforce.New(bruteforce.NewMemoryStore(), &bruteforce.Config{MaxFailures: 1, DelayStep: -1})
+	require.NoError(t, err)
+
+	var result *callback.PasswordVerifyResult
+	var callbackErr error
+	passwordVerifyCallback := l.Wrap(func(_ context.Context, _ *passwordverifyrequest.DTO) (*callback.PasswordVerifyResult, error) {
+		return result, callbackErr
+	})
+
+	_, err = passwordVerifyCallback(ctx, newRequest("pro-1", "jane", "wrong"))
+	assert.ErrorContains(t, err, "passwordVerifyCallback returned empty result")
+
+	callbackErr = errors.New("database unavailable")
+	_, err = passwordVerifyCallback(ctx, newRequest("pro-1", "jane", "wrong"))
+	assert.ErrorIs(t, err, callbackErr)
+
+	// errors do not count as failures
+	locked, err := l.Locked(ctx, "pro-1", "jane")
+	require.NoError(t, err)
+	assert.False(t, locked)
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store := bruteforce.NewMemoryStore()
+
+	failures, reserved, err := store.Reserve(ctx, "pro-1/jane", now, time.Minute, 2)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Equal(t, 0, failures)
+
+	failures, reserved, err = store.Reserve(ctx, "pro-1/jane", now.Add(30*time.Second), time.Minute, 2)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Equal(t, 1, failures)
+
+	// limit reached
+	failures, reserved, err = store.Reserve(ctx, "pro-1/jane", now.Add(40*time.Second), time.Minute, 2)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, 2, failures)
+
+	require.NoError(t, store.Release(ctx, "pro-1/jane", now.Add(30*time.Second)))
+
+	failures, err = store.Failures(ctx, "pro-1/jane", now.Add(40*time.Second), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	failures, reserved, err = store.Reserve(ctx, "pro-1/jane", now.Add(50*time.Second), time.Minute, 2)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Equal(t, 1, failures)
+
+	// sliding window, the first failure dropped out
+	failures, err = store.Failures(ctx, "pro-1/jane", now.Add(time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	failures, err = store.Failures(ctx, "pro-1/jane", now.Add(2*time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0, failures)
+}
@@ -0,0 +1,122 @@
+package bruteforce
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory store, it only works for a single instance (use a shared store for replicas).
+type MemoryStore struct {
+	mutex    sync.Mutex
+	failures map[string][]time.Time
+	sweep    time.Time
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore returns new in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		failures: make(map[string][]time.Time),
+	}
+}
+
+// Failures returns the number of failures of given key within the sliding window ending at now.
+func (m *MemoryStore) Failures(_ context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return len(m.trim(key, now, window)), nil
+}
+
+// Reserve records an attempt of given key at now as failure if there are fewer than max failures within the
+// sliding window and returns the number of failures before the attempt.
+func (m *MemoryStore) Reserve(_ context.Context, key string, now time.Time, window time.Duration, max int) (int, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.removeExpired(now, window)
+
+	failures := m.trim(key, now, window)
+	if len(failures) >= max {
+		return len(failures), false, nil
+	}
+
+	// failures are kept in order (callers determine now before the lock is acquired)
+	i := sort.Search(len(failures), func(i int) bool { return failures[i].After(now) })
+	m.failures[key] = append(failures[:i:i], append([]time.Time{now}, failures[i:]...)...)
+
+	return len(failures), true, nil
+}
+
+// Release removes the attempt of given key recorded at given time.
+func (m *MemoryStore) Release(_ context.Context, key string, at time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	failures := m.failures[key]
+	for i := range failures {
+		if failures[i].Equal(at) {
+			failures = append(failures[:i:i], failures[i+1:]...)
+
+			break
+		}
+	}
+
+	if len(failures) == 0 {
+		delete(m.failures, key)
+	} else {
+		m.failures[key] = failures
+	}
+
+	return nil
+}
+
+// Reset removes all failures of given key.
+func (m *MemoryStore) Reset(_ context.Context, key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.failures, key)
+
+	return nil
+}
+
+// trim removes the failures of given key which are outside the window and returns the remaining ones.
+func (m *MemoryStore) trim(key string, now time.Time, window time.Duration) []time.Time {
+	failures := m.failures[key]
+
+	start := now.Add(-window)
+	i := 0
+	for i < len(failures) && !failures[i].After(start) {
+		i++
+	}
+
+	if i == len(failures) {
+		delete(m.failures, key)
+
+		return nil
+	}
+
+	if i > 0 {
+		failures = append([]time.Time(nil), failures[i:]...)
+		m.failures[key] = failures
+	}
+
+	return failures
+}
+
+// removeExpired removes all keys without failures in the window, at most once a minute.
+func (m *MemoryStore) removeExpired(now time.Time, window time.Duration) {
+	if now.Before(m.sweep) {
+		return
+	}
+
+	for key := range m.failures {
+		m.trim(key, now, window)
+	}
+
+	m.sweep = now.Add(time.Minute)
+}
@@ -1,6 +1,99 @@
 package callback
 
-import "github.com/corbado/webhook-go/pkg/dto/authmethodsresponse"
+import (
+	"context"
 
+	"github.com/corbado/webhook-go/pkg/dto/authmethodsrequest"
+	"github.com/corbado/webhook-go/pkg/dto/authmethodsresponse"
+	"github.com/corbado/webhook-go/pkg/dto/passwordverifyrequest"
+)
+
+// AuthMethods is executed for action 'authMethods'. Like all other callbacks it can return a webhookerr.Error
+// (or an error wrapping it) to control the HTTP status code and the public message of the response, all other
+// errors result in an internal server error.
 type AuthMethods func(username string) (authmethodsresponse.Status, error)
 type PasswordVerify func(username string, password string) (bool, error)
+
+// AuthMethodsContext is the context-aware variant of AuthMethods. The given context is derived from the
+// HTTP request (and therefore canceled when the request is canceled), req is the full parsed request.
+type AuthMethodsContext func(ctx context.Context, req *authmethodsrequest.DTO) (authmethodsresponse.Status, error)
+
+// PasswordVerifyContext is the context-aware variant of PasswordVerify. The given context is derived from the
+// HTTP request (and therefore canceled when the request is canceled), req is the full parsed request.
+type PasswordVerifyContext func(ctx context.Context, req *passwordverifyrequest.DTO) (bool, error)
+
+// AdaptAuthMethods returns given callback as context-aware callback (nil if given callback is nil).
+func AdaptAuthMethods(authMethodsCallback AuthMethods) AuthMethodsContext {
+	if authMethodsCallback == nil {
+		return nil
+	}
+
+	return func(_ context.Context, req *authmethodsrequest.DTO) (authmethodsresponse.Status, error) {
+		return authMethodsCallback(req.Data.Username)
+	}
+}
+
+// AdaptPasswordVerify returns given callback as context-aware callback (nil if given callback is nil).
+func AdaptPasswordVerify(passwordVerifyCallback PasswordVerify) PasswordVerifyContext {
+	if passwordVerifyCallback == nil {
+		return nil
+	}
+
+	return func(_ context.Context, req *passwordverifyrequest.DTO) (bool, error) {
+		return passwordVerifyCallback(req.Data.Username, req.Data.Password.Value())
+	}
+}
+
+// AuthMethodsResult is the result of an AuthMethodsWithResult callback. If ResponseID is empty the
+// generated responseID (if any) is used.
+type AuthMethodsResult struct {
+	ResponseID string
+	Status     authmethodsresponse.Status
+}
+
+// PasswordVerifyResult is the result of a PasswordVerifyWithResult callback. If ResponseID is empty the
+// generated responseID (if any) is used.
+type PasswordVerifyResult struct {
+	ResponseID string
+	Success    bool
+}
+
+// AuthMethodsWithResult is the variant of AuthMethodsContext which can control the responseID that shows
+// up in the webhook log in the developer panel.
+type AuthMethodsWithResult func(ctx context.Context, req *authmethodsrequest.DTO) (*AuthMethodsResult, error)
+
+// PasswordVerifyWithResult is the variant of PasswordVerifyContext which can control the responseID that shows
+// up in the webhook log in the developer panel.
+type PasswordVerifyWithResult func(ctx context.Context, req *passwordverifyrequest.DTO) (*PasswordVerifyResult, error)
+
+// AdaptAuthMethodsContext returns given callback as callback with result (nil if given callback is nil).
+func AdaptAuthMethodsContext(authMethodsCallback AuthMethodsContext) AuthMethodsWithResult {
+	if authMethodsCallback == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, req *authmethodsrequest.DTO) (*AuthMethodsResult, error) {
+		status, err := authMethodsCallback(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return &AuthMethodsResult{Status: status}, nil
+	}
+}
+
+// AdaptPasswordVerifyContext returns given callback as callback with result (nil if given callback is nil).
+func AdaptPasswordVerifyContext(passwordVerifyCallback PasswordVerifyContext) PasswordVerifyWithResult {
+	if passwordVerifyCallback == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, req *passwordverifyrequest.DTO) (*PasswordVerifyResult, error) {
+		success, err := passwordVerifyCallback(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return &PasswordVerifyResult{Success: success}, nil
+	}
+}
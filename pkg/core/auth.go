@@ -0,0 +1,123 @@
+package core
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/corbado/webhook-go/pkg/auth"
+	"github.com/corbado/webhook-go/pkg/logger"
+	"github.com/corbado/webhook-go/pkg/webhookerr"
+)
+
+// checkAuthentication authenticates the request (authenticator and signature headers, the signature of the
+// body is verified while decoding) and returns the error response if authentication failed or the client
+// address is locked, nil otherwise.
+func (c *Core) checkAuthentication(x *exchange, req *Request) *Response {
+	if c.lockoutEnabled(x) {
+		state, err := c.lockout.Check(x.ctx, x.clientIP.String(), time.Now())
+		if err != nil {
+			return x.errorResponse(err)
+		}
+
+		if retryAfter := state.RetryAfter(time.Now()); retryAfter > 0 {
+			x.logger.Log(logger.LevelWarn, "Client address is locked", logger.F("clientIP", x.clientIP.String()), logger.F("retryAfter", retryAfter.String()))
+
+			return x.errorResponse(webhookerr.TooManyRequests("Too many failed authentication attempts", retryAfter))
+		}
+
+		if state != nil {
+			x.lockoutFailures = state.Failures
+		}
+	}
+
+	if c.authenticator != nil {
+		principal, err := c.authenticator.Authenticate(x.ctx, &auth.Request{
+			Method:   req.Method,
+			URL:      req.URL,
+			Header:   req.Header,
+			ClientIP: x.clientIP,
+		})
+		if err != nil {
+			if !errors.Is(err, auth.ErrUnauthorized) {
+				return x.errorResponse(err)
+			}
+
+			c.authFailed(x, err)
+
+			return x.unauthorized(auth.Challenge(c.authenticator))
+		}
+
+		x.principal = principal
+	}
+
+	if c.signatureVerifier == nil {
+		c.authSucceeded(x)
+
+		return nil
+	}
+
+	verification, err := c.signatureVerifier.Begin(req.Header, time.Now())
+	if err != nil {
+		c.authFailed(x, err)
+
+		return x.errorResponse(err)
+	}
+
+	x.signature = verification
+
+	return nil
+}
+
+// lockoutEnabled returns true if the lockout is configured and the request has a valid client address (invalid
+// addresses would all share one lockout key, so one client could lock out all others).
+func (c *Core) lockoutEnabled(x *exchange) bool {
+	return c.lockout != nil && x.clientIP.IsValid()
+}
+
+// authSucceeded resets the lockout of the client address (if it has failures) once the request passed all
+// authentication checks.
+func (c *Core) authSucceeded(x *exchange) {
+	if !c.lockoutEnabled(x) || x.lockoutFailures == 0 {
+		return
+	}
+
+	if err := c.lockout.Reset(x.ctx, x.clientIP.String()); err != nil {
+		x.logger.Log(logger.LevelError, "Resetting lockout failed", logger.Err(err), logger.F("clientIP", x.clientIP.String()))
+	}
+}
+
+// authFailed reports a failed authentication through the logger and the configured hooks and records it in
+// the lockout (if configured).
+func (c *Core) authFailed(x *exchange, err error) {
+	x.logger.Log(logger.LevelWarn, "Authentication failed", logger.F("clientIP", x.clientIP.String()), logger.F("reason", err.Error()))
+
+	for _, h := range c.hooks {
+		if h.OnAuthFailure != nil {
+			h.OnAuthFailure(x.ctx, x.clientIP)
+		}
+	}
+
+	if !c.lockoutEnabled(x) {
+		return
+	}
+
+	duration, err := c.lockout.Failure(x.ctx, x.clientIP.String(), time.Now())
+	if err != nil {
+		x.logger.Log(logger.LevelError, "Recording authentication failure failed", logger.Err(err), logger.F("clientIP", x.clientIP.String()))
+
+		return
+	}
+
+	if duration == 0 {
+		return
+	}
+
+	x.logger.Log(logger.LevelWarn, "Client address locked after repeated authentication failures", logger.F("clientIP", x.clientIP.String()), logger.F("duration", duration.String()))
+
+	for _, h := range c.hooks {
+		if h.OnLockout != nil {
+			h.OnLockout(x.ctx, x.clientIP, duration)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/corbado/webhook-go/pkg/webhookerr"
+)
+
+// DefaultMaxBodySize is the maximum size of request bodies (in bytes) if none is configured.
+const DefaultMaxBodySize = 64 << 10
+
+// limitedReader reads from the underlying reader until the limit is exceeded, in this case it returns an
+// error resulting in status code 413 (like http.MaxBytesReader but without depending on a response writer).
+type limitedReader struct {
+	reader    io.Reader
+	limit     int64
+	remaining int64
+	err       error
+}
+
+func newLimitedReader(reader io.Reader, limit int64) *limitedReader {
+	return &limitedReader{
+		reader:    reader,
+		limit:     limit,
+		remaining: limit,
+	}
+}
+
+// Read reads from the underlying reader (one more byte than the remaining limit to detect bodies which are
+// too large).
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.reader.Read(p)
+	if int64(n) <= l.remaining {
+		l.remaining -= int64(n)
+
+		return n, err
+	}
+
+	n = int(l.remaining)
+	l.remaining = 0
+	l.err = webhookerr.New(http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body too large, maximum is %d bytes", l.limit))
+
+	return n, l.err
+}
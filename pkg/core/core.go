@@ -0,0 +1,532 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/corbado/webhook-go/pkg/action"
+	"github.com/corbado/webhook-go/pkg/audit"
+	"github.com/corbado/webhook-go/pkg/auth"
+	"github.com/corbado/webhook-go/pkg/credential"
+	"github.com/corbado/webhook-go/pkg/hooks"
+	"github.com/corbado/webhook-go/pkg/ipfilter"
+	"github.com/corbado/webhook-go/pkg/lockout"
+	"github.com/corbado/webhook-go/pkg/logger"
+	"github.com/corbado/webhook-go/pkg/replay"
+	"github.com/corbado/webhook-go/pkg/responseid"
+	"github.com/corbado/webhook-go/pkg/signature"
+	"github.com/corbado/webhook-go/pkg/webhookerr"
+)
+
+// Config holds everything the core needs to handle webhook requests.
+type Config struct {
+	Logger logger.Logger
+
+	// Credentials are the accepted Basic Auth credentials, the first valid one which matches is used. Can be
+	// empty if Authenticator or SignatureVerifier is given.
+	Credentials []*credential.Credential
+
+	// Authenticator is optional, if given it authenticates requests instead of Basic Auth with Credentials
+	// (use auth.NewBasic() to combine Basic Auth with other authenticators).
+	Authenticator auth.Authenticator
+
+	// IPFilter is optional, if given only requests from allowed client addresses are accepted (checked
+	// before authentication).
+	IPFilter *ipfilter.Filter
+
+	// ClientIPResolver is optional, if given the client address is resolved from the forwarding header of
+	// trusted proxies without restricting client addresses (cannot be given with IPFilter, which resolves
+	// client addresses itself).
+	ClientIPResolver *ipfilter.Resolver
+
+	// Lockout is optional, if given client addresses are locked after repeated authentication failures
+	// (authenticator and signature). Requests without valid client address are not locked out.
+	Lockout *lockout.Lockout
+
+	// ReplayGuard is optional, if given request ids are remembered to reject (or replay) duplicate requests.
+	ReplayGuard *replay.Guard
+
+	// SignatureVerifier is optional, if given all requests must be signed (in addition to Basic Auth if
+	// Credentials are given).
+	SignatureVerifier *signature.Verifier
+
+	// Actions maps action names (value of the X-Corbado-Action header) to their handlers.
+	Actions map[string]action.Handler
+
+	// ResponseIDGenerator is optional, without generator responseIDs are only set by callbacks.
+	ResponseIDGenerator responseid.Generator
+
+	// ErrorFormat defines how error responses are sent, defaults to ErrorFormatJSON.
+	ErrorFormat ErrorFormat
+
+	// Timeouts maps action names to their timeouts (optional, actions without timeout have no deadline).
+	Timeouts map[string]*Timeout
+
+	// TracerProvider is optional, the global tracer provider (see otel.GetTracerProvider()) is used if empty.
+	TracerProvider trace.TracerProvider
+
+	// Propagator is optional, it extracts the trace context from the request headers (W3C trace context is
+	// used if empty).
+	Propagator propagation.TextMapPropagator
+
+	// MinDurations maps action names to the minimum duration of their responses (optional, response time
+	// equalization).
+	MinDurations map[string]*MinDuration
+
+	// Auditor is optional, if given an audit record is written for every request.
+	Auditor *audit.Auditor
+
+	// Hooks are optional.
+	Hooks []*hooks.Hooks
+
+	// MaxBodySize is the maximum size of request bodies in bytes, defaults to DefaultMaxBodySize.
+	MaxBodySize int64
+}
+
+// Core implements the framework-agnostic handling of webhook requests (authentication, dispatching
+// of actions, decoding of requests and encoding of responses). The framework specific handlers only
+// translate between their request/response types and Request/Response.
+type Core struct {
+	logger              logger.Leveled
+	authenticator       auth.Authenticator
+	signatureVerifier   *signature.Verifier
+	ipFilter            *ipfilter.Filter
+	clientIPResolver    *ipfilter.Resolver
+	lockout             *lockout.Lockout
+	replayGuard         *replay.Guard
+	actions             map[string]action.Handler
+	responseIDGenerator responseid.Generator
+	errorFormat         ErrorFormat
+	timeouts            map[string]*Timeout
+	minDurations        map[string]*MinDuration
+	tracer              trace.Tracer
+	propagator          propagation.TextMapPropagator
+	auditor             *audit.Auditor
+	hooks               []*hooks.Hooks
+	maxBodySize         int64
+}
+
+// New returns new core instance.
+func New(config *Config) (*Core, error) {
+	if config == nil {
+		return nil, errors.New("empty parameter config")
+	}
+
+	if config.Logger == nil {
+		return nil, errors.New("empty parameter config.Logger")
+	}
+
+	if config.ErrorFormat != ErrorFormatJSON && config.ErrorFormat != ErrorFormatText {
+		return nil, errors.Errorf("invalid parameter config.ErrorFormat (%d)", config.ErrorFormat)
+	}
+
+	authenticator := config.Authenticator
+	if len(config.Credentials) > 0 {
+		if authenticator != nil {
+			return nil, errors.New("config.Credentials and config.Authenticator cannot both be given")
+		}
+
+		basic, err := auth.NewBasic(config.Credentials)
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid parameter config.Credentials")
+		}
+
+		authenticator = basic
+	}
+
+	if authenticator == nil && config.SignatureVerifier == nil {
+		return nil, errors.New("empty parameter config.Credentials (and no config.Authenticator or config.SignatureVerifier given)")
+	}
+
+	if config.IPFilter != nil && config.ClientIPResolver != nil {
+		return nil, errors.New("config.IPFilter and config.ClientIPResolver cannot both be given")
+	}
+
+	if len(config.Actions) == 0 {
+		return nil, errors.New("empty parameter config.Actions")
+	}
+
+	actions := make(map[string]action.Handler, len(config.Actions))
+	for name, handler := range config.Actions {
+		if name == "" {
+			return nil, errors.New("empty action name in config.Actions")
+		}
+
+		if handler == nil {
+			return nil, errors.Errorf("empty handler for action '%s' in config.Actions", name)
+		}
+
+		actions[name] = handler
+	}
+
+	timeouts := make(map[string]*Timeout, len(config.Timeouts))
+	for name, timeout := range config.Timeouts {
+		if _, exists := actions[name]; !exists {
+			return nil, errors.Errorf("timeout given for unknown action '%s' in config.Timeouts", name)
+		}
+
+		if timeout == nil || timeout.Duration <= 0 {
+			return nil, errors.Errorf("timeout for action '%s' in config.Timeouts must be greater than zero", name)
+		}
+
+		timeouts[name] = timeout
+	}
+
+	minDurations := make(map[string]*MinDuration, len(config.MinDurations))
+	for name, minDuration := range config.MinDurations {
+		if _, exists := actions[name]; !exists {
+			return nil, errors.Errorf("minimum duration given for unknown action '%s' in config.MinDurations", name)
+		}
+
+		if minDuration == nil || minDuration.Duration <= 0 || minDuration.Jitter < 0 {
+			return nil, errors.Errorf("minimum duration for action '%s' in config.MinDurations must be greater than zero (jitter must not be negative)", name)
+		}
+
+		minDurations[name] = minDuration
+	}
+
+	for _, h := range config.Hooks {
+		if h == nil {
+			return nil, errors.New("empty hooks in config.Hooks")
+		}
+	}
+
+	tracerProvider := config.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+
+	propagator := config.Propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+
+	maxBodySize := config.MaxBodySize
+	if maxBodySize < 0 {
+		return nil, errors.Errorf("invalid parameter config.MaxBodySize (%d)", maxBodySize)
+	}
+
+	if maxBodySize == 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+
+	return &Core{
+		logger:              logger.NewLeveled(config.Logger),
+		authenticator:       authenticator,
+		signatureVerifier:   config.SignatureVerifier,
+		ipFilter:            config.IPFilter,
+		clientIPResolver:    config.ClientIPResolver,
+		lockout:             config.Lockout,
+		replayGuard:         config.ReplayGuard,
+		actions:             actions,
+		responseIDGenerator: config.ResponseIDGenerator,
+		errorFormat:         config.ErrorFormat,
+		timeouts:            timeouts,
+		minDurations:        minDurations,
+		tracer:              tracerProvider.Tracer(TracerName),
+		propagator:          propagator,
+		auditor:             config.Auditor,
+		hooks:               config.Hooks,
+		maxBodySize:         maxBodySize,
+	}, nil
+}
+
+// Logger returns the (leveled) logger of the core.
+func (c *Core) Logger() logger.Leveled {
+	return c.logger
+}
+
+// Handle handles the given webhook request and returns the response which should be sent back. Panics
+// are recovered and result in an internal server error. Responses of actions with a minimum duration are
+// delayed accordingly.
+func (c *Core) Handle(req *Request) (resp *Response) {
+	x := c.newExchange(req)
+	c.startRequestSpan(x, req)
+
+	defer c.pad(x)
+
+	defer func() {
+		c.finish(x, resp)
+	}()
+
+	defer func() {
+		if value := recover(); value != nil {
+			resp = x.internalServerError(c.recovered(x.ctx, x.action, value))
+		}
+	}()
+
+	return c.handle(x, req)
+}
+
+func (c *Core) handle(x *exchange, req *Request) *Response {
+	x.logger.Log(logger.LevelDebug, "Handling webhook request", logger.F("method", req.Method), logger.F("url", req.URL))
+
+	if c.ipFilter != nil {
+		x.clientIP = c.ipFilter.ClientIP(req.RemoteAddr, req.Header)
+		if !c.ipFilter.Allowed(x.clientIP) {
+			x.logger.Log(logger.LevelWarn, "Client address not allowed", logger.F("clientIP", x.clientIP.String()))
+
+			return x.forbidden()
+		}
+	} else if c.clientIPResolver != nil {
+		x.clientIP = c.clientIPResolver.ClientIP(req.RemoteAddr, req.Header)
+	} else {
+		x.clientIP = ipfilter.ClientIPFromRemoteAddr(req.RemoteAddr)
+	}
+
+	end := c.startSpan(x, "webhook.auth")
+	resp := c.checkAuthentication(x, req)
+	end(responseError(resp))
+
+	if resp != nil {
+		return resp
+	}
+
+	if req.Method != http.MethodPost {
+		return x.badRequest("Invalid method '%s', only POST is allowed", req.Method)
+	}
+
+	x.action = req.Header.Get("X-Corbado-Action")
+	if x.action == "" {
+		return x.badRequest("X-Corbado-Action header missing or empty")
+	}
+
+	x.with(logger.F("action", x.action))
+
+	if req.Body == nil {
+		return x.badRequest("Empty body, provide JSON request")
+	}
+
+	var limited io.Reader = newLimitedReader(req.Body, c.maxBodySize)
+	if x.signature != nil {
+		limited = io.TeeReader(limited, x.signature)
+	}
+
+	body := bufio.NewReader(limited)
+	if _, err := body.Peek(1); err != nil {
+		if errors.Is(err, io.EOF) {
+			return x.badRequest("Empty body, provide JSON request")
+		}
+
+		return x.errorResponse(errors.WithStack(err))
+	}
+
+	handler, ok := c.actions[x.action]
+	if !ok {
+		return x.badRequest("Invalid action given in X-Corbado-Action header ('%s')", x.action)
+	}
+
+	return c.handleAction(x, handler, body)
+}
+
+func (c *Core) handleAction(x *exchange, handler action.Handler, body io.Reader) *Response {
+	req, err := c.decode(x, handler, body)
+	if err != nil {
+		return x.errorResponse(err)
+	}
+
+	if identifiable, ok := req.(action.Identifiable); ok {
+		x.requestID = identifiable.GetID()
+		x.projectID = identifiable.GetProjectID()
+		x.with(logger.F("requestID", x.requestID), logger.F("projectID", x.projectID))
+	}
+
+	if usernameGetter, ok := req.(action.UsernameGetter); ok {
+		x.username = usernameGetter.GetUsername()
+	}
+
+	if c.replayGuard != nil && x.requestID != "" {
+		return c.handleGuarded(x, func() *Response {
+			return c.dispatch(x, handler, req)
+		})
+	}
+
+	return c.dispatch(x, handler, req)
+}
+
+// decode decodes the request and verifies the signature of the body (if configured).
+func (c *Core) decode(x *exchange, handler action.Handler, body io.Reader) (req any, err error) {
+	end := c.startSpan(x, "webhook.decode")
+	defer func() {
+		end(err)
+	}()
+
+	req, err = handler.Decode(body)
+
+	// the signature is verified before decoding errors are sent to not leak anything to unsigned requests
+	if x.signature != nil {
+		if _, drainErr := io.Copy(io.Discard, body); drainErr != nil {
+			return nil, errors.WithStack(drainErr)
+		}
+
+		if verifyErr := x.signature.Verify(); verifyErr != nil {
+			c.authFailed(x, verifyErr)
+
+			return nil, verifyErr
+		}
+
+		c.authSucceeded(x)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// dispatch validates the decoded request, executes the handler and encodes the response.
+func (c *Core) dispatch(x *exchange, handler action.Handler, req any) *Response {
+	if err := c.generateResponseID(x); err != nil {
+		return x.errorResponse(err)
+	}
+
+	if err := handler.Validate(req); err != nil {
+		if _, ok := webhookerr.As(err); ok {
+			return x.errorResponse(err)
+		}
+
+		return x.badRequest("%s", err.Error())
+	}
+
+	var resp any
+	var err error
+
+	end := c.startSpan(x, "webhook.callback")
+
+	if timeout, exists := c.timeouts[x.action]; exists {
+		resp, err = c.handleWithTimeout(x, handler, req, timeout)
+	} else {
+		resp, err = c.handleSafely(x.ctx, x.action, handler, req)
+	}
+
+	end(err)
+
+	if err != nil {
+		return x.errorResponse(err)
+	}
+
+	if outcomer, ok := resp.(action.Outcomer); ok {
+		x.outcome = outcomer.Outcome()
+	}
+
+	// callbacks can set their own responseID (instead of the generated one)
+	if identifiable, ok := resp.(action.ResponseIdentifiable); ok {
+		if responseID := identifiable.GetResponseID(); responseID != "" && responseID != x.responseID {
+			x.responseID = responseID
+			x.with(logger.F("responseID", responseID))
+		}
+	}
+
+	encoded, err := handler.Encode(resp)
+	if err != nil {
+		return x.errorResponse(err)
+	}
+
+	return newJSONResponse(http.StatusOK, encoded)
+}
+
+// finish logs the summary of the handled request, writes the audit record and notifies the configured hooks.
+func (c *Core) finish(x *exchange, resp *Response) {
+	outcome := x.outcome
+	if resp.StatusCode >= http.StatusBadRequest {
+		outcome = "error"
+	}
+
+	duration := time.Since(x.start)
+
+	c.endRequestSpan(x, resp, outcome)
+
+	fields := []logger.Field{
+		logger.F("status", resp.StatusCode),
+		logger.F("outcome", outcome),
+		logger.F("duration", duration.String()),
+	}
+
+	// the principal shows which credential was used (label for Basic Auth), so it can be seen when an old
+	// credential stops being used
+	if x.principal != nil {
+		fields = append(fields, logger.F("principal", x.principal.Name), logger.F("scheme", x.principal.Scheme))
+	}
+
+	x.logger.Log(logger.LevelInfo, "Webhook request handled", fields...)
+
+	if c.auditor != nil {
+		c.audit(x, resp, outcome, duration)
+	}
+
+	// unknown actions are not passed to hooks to limit the cardinality of metrics for example
+	actionName := x.action
+	if _, exists := c.actions[actionName]; !exists {
+		actionName = ""
+	}
+
+	request := &hooks.Request{
+		Action:     actionName,
+		ProjectID:  x.projectID,
+		RequestID:  x.requestID,
+		ResponseID: x.responseID,
+		StatusCode: resp.StatusCode,
+		Outcome:    outcome,
+		Duration:   duration,
+	}
+
+	for _, h := range c.hooks {
+		if h.OnRequest != nil {
+			h.OnRequest(x.ctx, request)
+		}
+	}
+}
+
+// audit writes the audit record of the handled request, failures are logged only (the response is already
+// determined).
+func (c *Core) audit(x *exchange, resp *Response, outcome string, duration time.Duration) {
+	record := &audit.Record{
+		Timestamp:  x.start,
+		RequestID:  x.requestID,
+		ProjectID:  x.projectID,
+		Action:     x.action,
+		Outcome:    outcome,
+		StatusCode: resp.StatusCode,
+		LatencyMs:  float64(duration) / float64(time.Millisecond),
+		ResponseID: x.responseID,
+	}
+
+	if x.principal != nil {
+		record.Principal = x.principal.Name
+	}
+
+	if err := c.auditor.Write(x.ctx, record, x.username); err != nil {
+		x.logger.Log(logger.LevelError, "Writing audit record failed", logger.Err(err))
+	}
+}
+
+// generateResponseID generates the responseID for the (decoded) request if a generator is configured
+// and attaches it to the context and the logger of the exchange.
+func (c *Core) generateResponseID(x *exchange) error {
+	if c.responseIDGenerator == nil {
+		return nil
+	}
+
+	responseID, err := c.responseIDGenerator(&responseid.Request{
+		Action:    x.action,
+		ID:        x.requestID,
+		ProjectID: x.projectID,
+	})
+	if err != nil {
+		return err
+	}
+
+	x.responseID = responseID
+	x.ctx = responseid.NewContext(x.ctx, responseID)
+	x.with(logger.F("responseID", responseID))
+
+	return nil
+}
@@ -0,0 +1,1197 @@
+package core_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/corbado/webhook-go/pkg/action"
+	"github.com/corbado/webhook-go/pkg/audit"
+	"github.com/corbado/webhook-go/pkg/callback"
+	"github.com/corbado/webhook-go/pkg/core"
+	"github.com/corbado/webhook-go/pkg/credential"
+	"github.com/corbado/webhook-go/pkg/dto/authmethodsrequest"
+	"github.com/corbado/webhook-go/pkg/dto/authmethodsresponse"
+	"github.com/corbado/webhook-go/pkg/dto/passwordverifyrequest"
+	"github.com/corbado/webhook-go/pkg/hooks"
+	"github.com/corbado/webhook-go/pkg/ipfilter"
+	"github.com/corbado/webhook-go/pkg/lockout"
+	"github.com/corbado/webhook-go/pkg/logger"
+	"github.com/corbado/webhook-go/pkg/replay"
+	"github.com/corbado/webhook-go/pkg/responseid"
+	"github.com/corbado/webhook-go/pkg/signature"
+	"github.com/corbado/webhook-go/pkg/webhookerr"
+)
+
+const username = "webhookUsername"
+const password = "webhookPassword"
+
+func TestHandle(t *testing.T) {
+	type contextKey struct{}
+
+	authMethodsHandler, err := action.NewAuthMethods(func(ctx context.Context, req *authmethodsrequest.DTO) (*callback.AuthMethodsResult, error) {
+		if ctx.Value(contextKey{}) != req.ID {
+			return nil, errors.New("context not passed")
+		}
+
+		if req.Data.Username == "custom" {
+			return &callback.AuthMethodsResult{ResponseID: "custom-1", Status: authmethodsresponse.StatusExists}, nil
+		}
+
+		return &callback.AuthMethodsResult{Status: authmethodsresponse.StatusNotExists}, nil
+	})
+	require.NoError(t, err)
+
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: newCredentials(t),
+		Actions: map[string]action.Handler{
+			action.AuthMethods: authMethodsHandler,
+		},
+		ResponseIDGenerator: responseid.NewFromRequest(nil),
+		ErrorFormat:         core.ErrorFormatText,
+	})
+	require.NoError(t, err)
+
+	newRequest := func(method string, action string, body string) *core.Request {
+		r, err := http.NewRequest(method, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, password)
+		if action != "" {
+			r.Header.Set("X-Corbado-Action", action)
+		}
+
+		return &core.Request{
+			Context: context.WithValue(context.Background(), contextKey{}, "who-1"),
+			Method:  r.Method,
+			URL:     r.URL.String(),
+			Header:  r.Header,
+			Body:    strings.NewReader(body),
+		}
+	}
+
+	resp := c.Handle(&core.Request{Method: http.MethodPost, Header: http.Header{}})
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, `Basic realm="restricted", charset="UTF-8"`, resp.Header.Get("WWW-Authenticate"))
+
+	resp = c.Handle(newRequest(http.MethodPut, "authMethods", ""))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "Invalid method 'PUT', only POST is allowed", string(resp.Body))
+
+	resp = c.Handle(newRequest(http.MethodPost, "authMethods", ""))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "Empty body, provide JSON request", string(resp.Body))
+
+	resp = c.Handle(newRequest(http.MethodPost, "authMethods", "broken"))
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Empty(t, resp.Body)
+
+	resp = c.Handle(newRequest(http.MethodPost, "authMethods", `{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `{"responseID":"who-1","data":{"status":"not_exists"}}`, string(resp.Body))
+
+	resp = c.Handle(newRequest(http.MethodPost, "authMethods", `{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"custom"}}`))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"responseID":"custom-1","data":{"status":"exists"}}`, string(resp.Body))
+}
+
+func TestHandleError(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(func(username string) (authmethodsresponse.Status, error) {
+		switch username {
+		case "unavailable":
+			return "", errors.Wrap(webhookerr.Unavailable(1500*time.Millisecond), "database down")
+		case "malformed":
+			return "", webhookerr.BadRequest("malformed username")
+		default:
+			return "", errors.New("unknown error")
+		}
+	})))
+	require.NoError(t, err)
+
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: newCredentials(t),
+		Actions: map[string]action.Handler{
+			action.AuthMethods: authMethodsHandler,
+		},
+	})
+	require.NoError(t, err)
+
+	newRequest := func(name string) *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, password)
+		r.Header.Set("X-Corbado-Action", "authMethods")
+
+		return &core.Request{
+			Method: r.Method,
+			Header: r.Header,
+			Body:   strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"` + name + `"}}`),
+		}
+	}
+
+	resp := c.Handle(newRequest("unavailable"))
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+	assert.Equal(t, `{"responseID":"","requestID":"who-1","error":{"code":"service_unavailable","message":"Service temporarily unavailable"}}`, string(resp.Body))
+
+	resp = c.Handle(newRequest("malformed"))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, `{"responseID":"","requestID":"who-1","error":{"code":"bad_request","message":"malformed username"}}`, string(resp.Body))
+
+	resp = c.Handle(newRequest("other"))
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `{"responseID":"","requestID":"who-1","error":{"code":"internal_server_error","message":"Internal Server Error"}}`, string(resp.Body))
+}
+
+func TestHandleTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	authMethodsHandler, err := action.NewAuthMethods(func(_ context.Context, _ *authmethodsrequest.DTO) (*callback.AuthMethodsResult, error) {
+		<-release
+
+		return &callback.AuthMethodsResult{Status: authmethodsresponse.StatusExists}, nil
+	})
+	require.NoError(t, err)
+
+	passwordVerifyHandler, err := action.NewPasswordVerify(func(ctx context.Context, _ *passwordverifyrequest.DTO) (*callback.PasswordVerifyResult, error) {
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	})
+	require.NoError(t, err)
+
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: newCredentials(t),
+		Actions: map[string]action.Handler{
+			action.AuthMethods:    authMethodsHandler,
+			action.PasswordVerify: passwordVerifyHandler,
+		},
+		Timeouts: map[string]*core.Timeout{
+			action.AuthMethods:    {Duration: 10 * time.Millisecond},
+			action.PasswordVerify: {Duration: 10 * time.Millisecond, Fallback: action.NewPasswordVerifyFallback(false)},
+		},
+	})
+	require.NoError(t, err)
+
+	newRequest := func(action string, body string) *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, password)
+		r.Header.Set("X-Corbado-Action", action)
+
+		return &core.Request{
+			Method: r.Method,
+			Header: r.Header,
+			Body:   strings.NewReader(body),
+		}
+	}
+
+	resp := c.Handle(newRequest("authMethods", `{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`))
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	assert.Equal(t, `{"responseID":"","requestID":"who-1","error":{"code":"gateway_timeout","message":"Callback timed out"}}`, string(resp.Body))
+
+	resp = c.Handle(newRequest("passwordVerify", `{"id":"who-2","projectID":"pro-1","action":"passwordVerify","data":{"username":"test","password":"test"}}`))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"responseID":"","data":{"success":false}}`, string(resp.Body))
+
+	_, err = core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: newCredentials(t),
+		Actions:     map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+		Timeouts:    map[string]*core.Timeout{"unknown": {Duration: time.Second}},
+	})
+	assert.ErrorContains(t, err, "timeout given for unknown action 'unknown' in config.Timeouts")
+}
+
+func TestHandleTimeoutPanic(t *testing.T) {
+	release := make(chan struct{})
+
+	authMethodsHandler, err := action.NewAuthMethods(func(_ context.Context, _ *authmethodsrequest.DTO) (*callback.AuthMethodsResult, error) {
+		<-release
+
+		panic("abandoned handler failed")
+	})
+	require.NoError(t, err)
+
+	panics := make(chan string, 1)
+
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: newCredentials(t),
+		Actions:     map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+		Timeouts:    map[string]*core.Timeout{action.AuthMethods: {Duration: 10 * time.Millisecond}},
+		Hooks: []*hooks.Hooks{{
+			OnPanic: func(_ context.Context, action string, _ error) {
+				panics <- action
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+	require.NoError(t, err)
+
+	r.SetBasicAuth(username, password)
+	r.Header.Set("X-Corbado-Action", "authMethods")
+
+	resp := c.Handle(&core.Request{
+		Method: r.Method,
+		Header: r.Header,
+		Body:   strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`),
+	})
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+
+	// the abandoned handler panics after the request has been handled
+	close(release)
+
+	select {
+	case action := <-panics:
+		assert.Equal(t, "authMethods", action)
+	case <-time.After(time.Second):
+		t.Fatal("panic of abandoned handler not reported")
+	}
+}
+
+func TestHandlePanic(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(func(_ context.Context, req *authmethodsrequest.DTO) (*callback.AuthMethodsResult, error) {
+		var result *callback.AuthMethodsResult
+		result.Status = authmethodsresponse.Status(req.Data.Username)
+
+		return result, nil
+	})
+	require.NoError(t, err)
+
+	var panics []error
+	var mu sync.Mutex
+
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: newCredentials(t),
+		Actions: map[string]action.Handler{
+			action.AuthMethods:    authMethodsHandler,
+			action.PasswordVerify: authMethodsHandler,
+		},
+		Timeouts: map[string]*core.Timeout{
+			action.PasswordVerify: {Duration: time.Second},
+		},
+		Hooks: []*hooks.Hooks{{
+			OnPanic: func(_ context.Context, action string, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				panics = append(panics, errors.Wrap(err, action))
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	newRequest := func(action string) *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, password)
+		r.Header.Set("X-Corbado-Action", action)
+
+		return &core.Request{
+			Method: r.Method,
+			Header: r.Header,
+			Body:   strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`),
+		}
+	}
+
+	resp := c.Handle(newRequest("authMethods"))
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	// passwordVerify is executed in a goroutine because of the timeout
+	resp = c.Handle(newRequest("passwordVerify"))
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	require.Len(t, panics, 2)
+	assert.ErrorContains(t, panics[0], "authMethods: recovered from panic: runtime error: invalid memory address or nil pointer dereference")
+	assert.ErrorContains(t, panics[1], "passwordVerify: recovered from panic")
+	assert.Contains(t, fmt.Sprintf("%+v", panics[0]), "core_test.TestHandlePanic")
+}
+
+func TestHandleBodyTooLarge(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(func(_ string) (authmethodsresponse.Status, error) {
+		return authmethodsresponse.StatusExists, nil
+	})))
+	require.NoError(t, err)
+
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: newCredentials(t),
+		Actions: map[string]action.Handler{
+			action.AuthMethods: authMethodsHandler,
+		},
+		MaxBodySize: 100,
+	})
+	require.NoError(t, err)
+
+	newRequest := func(name string) *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, password)
+		r.Header.Set("X-Corbado-Action", "authMethods")
+
+		return &core.Request{
+			Method: r.Method,
+			Header: r.Header,
+			Body:   strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"` + name + `"}}`),
+		}
+	}
+
+	resp := c.Handle(newRequest("test"))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = c.Handle(newRequest(strings.Repeat("a", 100)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal(t, `{"responseID":"","requestID":"","error":{"code":"request_entity_too_large","message":"Request body too large, maximum is 100 bytes"}}`, string(resp.Body))
+}
+
+func newCredentials(t *testing.T) []*credential.Credential {
+	cred, err := credential.New(credential.DefaultLabel, username, password, time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	return []*credential.Credential{cred}
+}
+
+func TestHandleSignature(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(func(_ string) (authmethodsresponse.Status, error) {
+		return authmethodsresponse.StatusExists, nil
+	})))
+	require.NoError(t, err)
+
+	verifier, err := signature.NewVerifier("signatureSecret", "", "", 0)
+	require.NoError(t, err)
+
+	signer, err := signature.NewSigner("signatureSecret", "", "")
+	require.NoError(t, err)
+
+	body := `{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`
+
+	newRequest := func(basicAuth bool, signedBody string) *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		if basicAuth {
+			r.SetBasicAuth(username, password)
+		}
+
+		r.Header.Set("X-Corbado-Action", "authMethods")
+		signer.SignRequest(r, time.Now(), []byte(signedBody))
+
+		return &core.Request{
+			Method: r.Method,
+			Header: r.Header,
+			Body:   strings.NewReader(body),
+		}
+	}
+
+	// signature only
+	c, err := core.New(&core.Config{
+		Logger:            logger.NewNull(),
+		SignatureVerifier: verifier,
+		Actions:           map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+	})
+	require.NoError(t, err)
+
+	resp := c.Handle(newRequest(false, body))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = c.Handle(newRequest(false, "other"))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, `{"responseID":"","requestID":"","error":{"code":"unauthorized","message":"Unauthorized"}}`, string(resp.Body))
+
+	// signature and Basic Auth
+	c, err = core.New(&core.Config{
+		Logger:            logger.NewNull(),
+		Credentials:       newCredentials(t),
+		SignatureVerifier: verifier,
+		Actions:           map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+	})
+	require.NoError(t, err)
+
+	resp = c.Handle(newRequest(false, body))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = c.Handle(newRequest(true, body))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestHandleSignatureLockout(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(func(_ string) (authmethodsresponse.Status, error) {
+		return authmethodsresponse.StatusExists, nil
+	})))
+	require.NoError(t, err)
+
+	verifier, err := signature.NewVerifier("signatureSecret", "", "", 0)
+	require.NoError(t, err)
+
+	signer, err := signature.NewSigner("signatureSecret", "", "")
+	require.NoError(t, err)
+
+	store, err := lockout.NewMemoryStore(0)
+	require.NoError(t, err)
+
+	l, err := lockout.New(store, &lockout.Config{Threshold: 3, BaseDuration: time.Minute})
+	require.NoError(t, err)
+
+	authFailures := 0
+
+	c, err := core.New(&core.Config{
+		Logger:            logger.NewNull(),
+		SignatureVerifier: verifier,
+		Lockout:           l,
+		Actions:           map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+		Hooks: []*hooks.Hooks{{
+			OnAuthFailure: func(_ context.Context, _ netip.Addr) {
+				authFailures++
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	body := `{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`
+
+	newRequest := func(sign bool, signedBody string) *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.Header.Set("X-Corbado-Action", "authMethods")
+		if sign {
+			signer.SignRequest(r, time.Now(), []byte(signedBody))
+		}
+
+		return &core.Request{
+			Method:     r.Method,
+			Header:     r.Header,
+			Body:       strings.NewReader(body),
+			RemoteAddr: "10.0.0.1:1234",
+		}
+	}
+
+	// invalid signature of the body
+	resp := c.Handle(newRequest(true, "other"))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// successful request resets the failures
+	resp = c.Handle(newRequest(true, body))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// missing signature headers
+	resp = c.Handle(newRequest(false, ""))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = c.Handle(newRequest(true, "other"))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = c.Handle(newRequest(true, "other"))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// locked, even with valid signature
+	resp = c.Handle(newRequest(true, body))
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	assert.Equal(t, 4, authFailures)
+}
+
+func TestHandleReplay(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       replay.Mode
+		wantStatus int
+		wantBody   string
+		wantCalls  int
+	}{
+		{
+			name:       "reject",
+			mode:       replay.ModeReject,
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"responseID":"","requestID":"who-1","error":{"code":"conflict","message":"Duplicate request"}}`,
+			wantCalls:  2,
+		},
+		{
+			name:       "replay",
+			mode:       replay.ModeReplay,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"responseID":"","data":{"status":"exists"}}`,
+			wantCalls:  2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			authMethodsHandler, err := action.NewAuthMethods(callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(func(_ string) (authmethodsresponse.Status, error) {
+				calls++
+
+				return authmethodsresponse.StatusExists, nil
+			})))
+			require.NoError(t, err)
+
+			guard, err := replay.NewMemoryGuard(time.Minute, test.mode)
+			require.NoError(t, err)
+
+			c, err := core.New(&core.Config{
+				Logger:      logger.NewNull(),
+				Credentials: newCredentials(t),
+				ReplayGuard: guard,
+				Actions:     map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+			})
+			require.NoError(t, err)
+
+			newRequest := func(id string) *core.Request {
+				r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+				require.NoError(t, err)
+
+				r.SetBasicAuth(username, password)
+				r.Header.Set("X-Corbado-Action", "authMethods")
+
+				return &core.Request{
+					Method: r.Method,
+					Header: r.Header,
+					Body:   strings.NewReader(`{"id":"` + id + `","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`),
+				}
+			}
+
+			resp := c.Handle(newRequest("who-1"))
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			resp = c.Handle(newRequest("who-1"))
+			assert.Equal(t, test.wantStatus, resp.StatusCode)
+			assert.Equal(t, test.wantBody, string(resp.Body))
+
+			resp = c.Handle(newRequest("who-2"))
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			assert.Equal(t, test.wantCalls, calls)
+		})
+	}
+}
+
+func TestHandleReplayFallback(t *testing.T) {
+	var calls atomic.Int32
+	authMethodsHandler, err := action.NewAuthMethods(func(ctx context.Context, _ *authmethodsrequest.DTO) (*callback.AuthMethodsResult, error) {
+		// first call times out (fallback is used)
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+
+			return nil, ctx.Err()
+		}
+
+		return &callback.AuthMethodsResult{Status: authmethodsresponse.StatusExists}, nil
+	})
+	require.NoError(t, err)
+
+	guard, err := replay.NewMemoryGuard(time.Minute, replay.ModeReplay)
+	require.NoError(t, err)
+
+	var outcomes []string
+
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: newCredentials(t),
+		ReplayGuard: guard,
+		Actions:     map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+		Timeouts: map[string]*core.Timeout{
+			action.AuthMethods: {Duration: 10 * time.Millisecond, Fallback: action.NewAuthMethodsFallback(authmethodsresponse.StatusNotExists)},
+		},
+		Hooks: []*hooks.Hooks{{
+			OnRequest: func(_ context.Context, req *hooks.Request) {
+				outcomes = append(outcomes, req.Outcome)
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	newRequest := func() *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, password)
+		r.Header.Set("X-Corbado-Action", "authMethods")
+
+		return &core.Request{
+			Method: r.Method,
+			Header: r.Header,
+			Body:   strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`),
+		}
+	}
+
+	// fallback response is not remembered, the retry executes the callback
+	resp := c.Handle(newRequest())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"responseID":"","data":{"status":"not_exists"}}`, string(resp.Body))
+
+	resp = c.Handle(newRequest())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"responseID":"","data":{"status":"exists"}}`, string(resp.Body))
+
+	resp = c.Handle(newRequest())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"responseID":"","data":{"status":"exists"}}`, string(resp.Body))
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, []string{"not_exists", "exists", core.OutcomeReplayed}, outcomes)
+}
+
+func TestHandleLockout(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(func(_ string) (authmethodsresponse.Status, error) {
+		return authmethodsresponse.StatusExists, nil
+	})))
+	require.NoError(t, err)
+
+	store, err := lockout.NewMemoryStore(0)
+	require.NoError(t, err)
+
+	l, err := lockout.New(store, &lockout.Config{Threshold: 2, BaseDuration: time.Minute})
+	require.NoError(t, err)
+
+	var authFailures []string
+	var lockouts []time.Duration
+
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: newCredentials(t),
+		Lockout:     l,
+		Actions:     map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+		Hooks: []*hooks.Hooks{{
+			OnAuthFailure: func(_ context.Context, clientIP netip.Addr) {
+				authFailures = append(authFailures, clientIP.String())
+			},
+			OnLockout: func(_ context.Context, _ netip.Addr, duration time.Duration) {
+				lockouts = append(lockouts, duration)
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	newRequest := func(remoteAddr string, pass string) *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, pass)
+		r.Header.Set("X-Corbado-Action", "authMethods")
+
+		return &core.Request{
+			Method:     r.Method,
+			Header:     r.Header,
+			Body:       strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`),
+			RemoteAddr: remoteAddr,
+		}
+	}
+
+	resp := c.Handle(newRequest("10.0.0.1:1234", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// successful authentication resets the failures
+	resp = c.Handle(newRequest("10.0.0.1:1234", password))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = c.Handle(newRequest("10.0.0.1:1234", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = c.Handle(newRequest("10.0.0.1:1234", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// locked, even with valid credentials
+	resp = c.Handle(newRequest("10.0.0.1:1234", password))
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get("Retry-After"))
+	assert.Equal(t, `{"responseID":"","requestID":"","error":{"code":"too_many_requests","message":"Too many failed authentication attempts"}}`, string(resp.Body))
+
+	// other client addresses are not affected
+	resp = c.Handle(newRequest("10.0.0.2:1234", password))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.1", "10.0.0.1"}, authFailures)
+	assert.Equal(t, []time.Duration{time.Minute}, lockouts)
+}
+
+func TestHandleLockoutClientIP(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(func(_ string) (authmethodsresponse.Status, error) {
+		return authmethodsresponse.StatusExists, nil
+	})))
+	require.NoError(t, err)
+
+	store, err := lockout.NewMemoryStore(0)
+	require.NoError(t, err)
+
+	l, err := lockout.New(store, &lockout.Config{Threshold: 2, BaseDuration: time.Minute})
+	require.NoError(t, err)
+
+	resolver, err := ipfilter.NewResolver([]string{"10.0.0.1"}, ipfilter.ForwardedHeaderXForwardedFor)
+	require.NoError(t, err)
+
+	c, err := core.New(&core.Config{
+		Logger:           logger.NewNull(),
+		Credentials:      newCredentials(t),
+		ClientIPResolver: resolver,
+		Lockout:          l,
+		Actions:          map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+	})
+	require.NoError(t, err)
+
+	newRequest := func(remoteAddr string, forwardedFor string, pass string) *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, pass)
+		r.Header.Set("X-Corbado-Action", "authMethods")
+		r.Header.Set("X-Forwarded-For", forwardedFor)
+
+		return &core.Request{
+			Method:     r.Method,
+			Header:     r.Header,
+			Body:       strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`),
+			RemoteAddr: remoteAddr,
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		resp := c.Handle(newRequest("10.0.0.1:1234", "192.0.2.1", "wrong"))
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	resp := c.Handle(newRequest("10.0.0.1:1234", "192.0.2.1", password))
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	// other clients behind the same proxy are not affected
+	resp = c.Handle(newRequest("10.0.0.1:1234", "192.0.2.2", password))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// requests without valid client address are not locked out (they would all share one key)
+	for i := 0; i < 3; i++ {
+		resp = c.Handle(newRequest("invalid", "", "wrong"))
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	resp = c.Handle(newRequest("invalid", "", password))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = core.New(&core.Config{
+		Logger:           logger.NewNull(),
+		Credentials:      newCredentials(t),
+		IPFilter:         &ipfilter.Filter{},
+		ClientIPResolver: resolver,
+		Actions:          map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+	})
+	assert.ErrorContains(t, err, "config.IPFilter and config.ClientIPResolver cannot both be given")
+}
+
+func TestHandleMinDuration(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(func(username string) (authmethodsresponse.Status, error) {
+		if username == "invalid" {
+			return "", webhookerr.BadRequest("malformed username")
+		}
+
+		return authmethodsresponse.StatusNotExists, nil
+	})))
+	require.NoError(t, err)
+
+	echoHandler, err := action.New(
+		func(body io.Reader) (string, error) {
+			data, err := io.ReadAll(body)
+			return string(data), err
+		},
+		nil,
+		func(_ context.Context, req string) (string, error) {
+			return req, nil
+		},
+		func(resp string) ([]byte, error) {
+			return []byte(resp), nil
+		},
+	)
+	require.NoError(t, err)
+
+	_, err = core.New(&core.Config{
+		Logger:       logger.NewNull(),
+		Credentials:  newCredentials(t),
+		Actions:      map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+		MinDurations: map[string]*core.MinDuration{action.AuthMethods: {Duration: -time.Second}},
+	})
+	assert.ErrorContains(t, err, "minimum duration for action 'authMethods' in config.MinDurations must be greater than zero")
+
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: newCredentials(t),
+		Actions: map[string]action.Handler{
+			action.AuthMethods: authMethodsHandler,
+			"echo":             echoHandler,
+		},
+		MinDurations: map[string]*core.MinDuration{
+			action.AuthMethods: {Duration: 50 * time.Millisecond, Jitter: 10 * time.Millisecond},
+		},
+	})
+	require.NoError(t, err)
+
+	newRequest := func(actionName string, body string) *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, password)
+		r.Header.Set("X-Corbado-Action", actionName)
+
+		return &core.Request{
+			Method: r.Method,
+			Header: r.Header,
+			Body:   strings.NewReader(body),
+		}
+	}
+
+	start := time.Now()
+	resp := c.Handle(newRequest("authMethods", `{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// error responses are delayed as well
+	start = time.Now()
+	resp = c.Handle(newRequest("authMethods", `{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"invalid"}}`))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// actions without minimum duration are not delayed
+	start = time.Now()
+	resp = c.Handle(newRequest("echo", `{}`))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestHandleContextLogger(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(func(ctx context.Context, _ *authmethodsrequest.DTO) (*callback.AuthMethodsResult, error) {
+		logger.FromContext(ctx).Log(logger.LevelInfo, "Looking up user")
+
+		return &callback.AuthMethodsResult{Status: authmethodsresponse.StatusExists}, nil
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	c, err := core.New(&core.Config{
+		Logger:              logger.NewSlog(slog.New(slog.NewJSONHandler(&buf, nil))),
+		Credentials:         newCredentials(t),
+		Actions:             map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+		ResponseIDGenerator: responseid.NewFromRequest(nil),
+	})
+	require.NoError(t, err)
+
+	r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+	require.NoError(t, err)
+
+	r.SetBasicAuth(username, password)
+	r.Header.Set("X-Corbado-Action", "authMethods")
+
+	resp := c.Handle(&core.Request{
+		Method:     r.Method,
+		Header:     r.Header,
+		Body:       strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`),
+		RemoteAddr: "192.0.2.1:1234",
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+
+		records = append(records, record)
+	}
+
+	require.Len(t, records, 2)
+	assert.Equal(t, "Looking up user", records[0]["msg"])
+	assert.Equal(t, "Webhook request handled", records[1]["msg"])
+
+	for _, record := range records {
+		assert.Equal(t, "192.0.2.1:1234", record["remoteAddr"])
+		assert.Equal(t, "authMethods", record["action"])
+		assert.Equal(t, "who-1", record["requestID"])
+		assert.Equal(t, "pro-1", record["projectID"])
+		assert.Equal(t, "who-1", record["responseID"])
+	}
+
+	assert.Equal(t, "exists", records[1]["outcome"])
+	assert.Equal(t, credential.DefaultLabel, records[1]["principal"])
+	assert.Equal(t, "basic", records[1]["scheme"])
+}
+
+func TestHandleCallbackResponseID(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(func(_ context.Context, _ *authmethodsrequest.DTO) (*callback.AuthMethodsResult, error) {
+		return &callback.AuthMethodsResult{ResponseID: "cb-123", Status: authmethodsresponse.StatusExists}, nil
+	})
+	require.NoError(t, err)
+
+	var records []*audit.Record
+	auditor, err := audit.New(audit.SinkFunc(func(_ context.Context, record *audit.Record) error {
+		records = append(records, record)
+
+		return nil
+	}), func(_ string, username string) string {
+		return username
+	})
+	require.NoError(t, err)
+
+	var requests []*hooks.Request
+
+	var buf bytes.Buffer
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewSlog(slog.New(slog.NewJSONHandler(&buf, nil))),
+		Credentials: newCredentials(t),
+		Actions:     map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+		Auditor:     auditor,
+		Hooks: []*hooks.Hooks{{
+			OnRequest: func(_ context.Context, req *hooks.Request) {
+				requests = append(requests, req)
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+	require.NoError(t, err)
+
+	r.SetBasicAuth(username, password)
+	r.Header.Set("X-Corbado-Action", "authMethods")
+
+	resp := c.Handle(&core.Request{
+		Method: r.Method,
+		Header: r.Header,
+		Body:   strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`),
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"responseID":"cb-123","data":{"status":"exists"}}`, string(resp.Body))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	var summary map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &summary))
+	assert.Equal(t, "Webhook request handled", summary["msg"])
+	assert.Equal(t, "cb-123", summary["responseID"])
+
+	require.Len(t, records, 1)
+	assert.Equal(t, "cb-123", records[0].ResponseID)
+
+	require.Len(t, requests, 1)
+	assert.Equal(t, "cb-123", requests[0].ResponseID)
+}
+
+func TestHandleTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	passwordVerifyHandler, err := action.NewPasswordVerify(func(ctx context.Context, _ *passwordverifyrequest.DTO) (*callback.PasswordVerifyResult, error) {
+		_, span := tracerProvider.Tracer("test").Start(ctx, "db.query")
+		span.End()
+
+		return &callback.PasswordVerifyResult{Success: true}, nil
+	})
+	require.NoError(t, err)
+
+	c, err := core.New(&core.Config{
+		Logger:         logger.NewNull(),
+		Credentials:    newCredentials(t),
+		Actions:        map[string]action.Handler{action.PasswordVerify: passwordVerifyHandler},
+		TracerProvider: tracerProvider,
+	})
+	require.NoError(t, err)
+
+	r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+	require.NoError(t, err)
+
+	r.SetBasicAuth(username, password)
+	r.Header.Set("X-Corbado-Action", "passwordVerify")
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	resp := c.Handle(&core.Request{
+		Method: r.Method,
+		Header: r.Header,
+		Body:   strings.NewReader(`{"id":"pwv-1","projectID":"pro-1","action":"passwordVerify","data":{"username":"jane","password":"secretPassword"}}`),
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+
+		for _, attr := range span.Attributes {
+			assert.NotContains(t, attr.Value.Emit(), "jane")
+			assert.NotContains(t, attr.Value.Emit(), "secretPassword")
+		}
+	}
+
+	require.Len(t, spans, 5)
+
+	server := spans["webhook"]
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Contains(t, server.Attributes, attribute.String("corbado.action", "passwordVerify"))
+	assert.Contains(t, server.Attributes, attribute.String("corbado.project_id", "pro-1"))
+	assert.Contains(t, server.Attributes, attribute.String("corbado.outcome", "success"))
+	assert.Contains(t, server.Attributes, attribute.Int("http.response.status_code", http.StatusOK))
+
+	for _, name := range []string{"webhook.auth", "webhook.decode", "webhook.callback"} {
+		assert.Equal(t, server.SpanContext.SpanID(), spans[name].Parent.SpanID(), name)
+	}
+
+	assert.Equal(t, spans["webhook.callback"].SpanContext.SpanID(), spans["db.query"].Parent.SpanID())
+
+	// failed authentication is recorded on the auth span
+	exporter.Reset()
+
+	r.SetBasicAuth(username, "wrong")
+	resp = c.Handle(&core.Request{Method: r.Method, Header: r.Header, Body: strings.NewReader(`{}`)})
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "webhook.auth" {
+			assert.Equal(t, codes.Error, span.Status.Code)
+		}
+	}
+}
+
+func TestHandleAudit(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(func(_ string) (authmethodsresponse.Status, error) {
+		return authmethodsresponse.StatusExists, nil
+	})))
+	require.NoError(t, err)
+
+	var records []*audit.Record
+	sink := audit.SinkFunc(func(_ context.Context, record *audit.Record) error {
+		records = append(records, record)
+
+		return nil
+	})
+
+	pseudonymizer, err := audit.NewHMACPseudonymizer([]byte("key"))
+	require.NoError(t, err)
+
+	auditor, err := audit.New(sink, pseudonymizer)
+	require.NoError(t, err)
+
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: newCredentials(t),
+		Auditor:     auditor,
+		Actions:     map[string]action.Handler{action.AuthMethods: authMethodsHandler},
+	})
+	require.NoError(t, err)
+
+	newRequest := func(password string) *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, password)
+		r.Header.Set("X-Corbado-Action", "authMethods")
+
+		return &core.Request{
+			Method: r.Method,
+			Header: r.Header,
+			Body:   strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`),
+		}
+	}
+
+	start := time.Now()
+
+	resp := c.Handle(newRequest(password))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = c.Handle(newRequest("invalid"))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	require.Len(t, records, 2)
+
+	assert.WithinRange(t, records[0].Timestamp, start, time.Now())
+	assert.Equal(t, "who-1", records[0].RequestID)
+	assert.Equal(t, "pro-1", records[0].ProjectID)
+	assert.Equal(t, "authMethods", records[0].Action)
+	assert.Equal(t, pseudonymizer("pro-1", "test"), records[0].Username)
+	assert.Equal(t, "exists", records[0].Outcome)
+	assert.Equal(t, http.StatusOK, records[0].StatusCode)
+	assert.Greater(t, records[0].LatencyMs, 0.0)
+	assert.Equal(t, credential.DefaultLabel, records[0].Principal)
+
+	assert.Empty(t, records[1].RequestID)
+	assert.Empty(t, records[1].Username)
+	assert.Empty(t, records[1].Principal)
+	assert.Equal(t, "error", records[1].Outcome)
+	assert.Equal(t, http.StatusUnauthorized, records[1].StatusCode)
+}
+
+func TestNewLegacy(t *testing.T) {
+	authMethodsCallback := func(_ string) (authmethodsresponse.Status, error) {
+		return authmethodsresponse.StatusExists, nil
+	}
+
+	passwordVerifyCallback := func(_ string, _ string) (bool, error) {
+		return true, nil
+	}
+
+	usernameHash := sha256.Sum256([]byte(username))
+	passwordHash := sha256.Sum256([]byte(password))
+
+	c, err := core.NewLegacy(nil, usernameHash, passwordHash, authMethodsCallback, passwordVerifyCallback)
+	assert.ErrorContains(t, err, "empty parameter logger")
+	assert.Nil(t, c)
+
+	c, err = core.NewLegacy(logger.NewNull(), usernameHash, passwordHash, nil, passwordVerifyCallback)
+	assert.ErrorContains(t, err, "empty parameter authMethodsCallback")
+	assert.Nil(t, c)
+
+	c, err = core.NewLegacy(logger.NewNull(), usernameHash, passwordHash, authMethodsCallback, nil)
+	assert.ErrorContains(t, err, "empty parameter passwordVerifyCallback")
+	assert.Nil(t, c)
+
+	c, err = core.NewLegacy(logger.NewNull(), usernameHash, passwordHash, authMethodsCallback, passwordVerifyCallback)
+	require.NoError(t, err)
+
+	newRequest := func(password string) *core.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth(username, password)
+		r.Header.Set("X-Corbado-Action", "authMethods")
+
+		return &core.Request{
+			Method: r.Method,
+			Header: r.Header,
+			Body:   strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"authMethods","data":{"username":"test"}}`),
+		}
+	}
+
+	resp := c.Handle(newRequest("invalid"))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = c.Handle(newRequest(password))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"responseID":"","data":{"status":"exists"}}`, string(resp.Body))
+}
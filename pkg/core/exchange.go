@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/corbado/webhook-go/pkg/auth"
+	"github.com/corbado/webhook-go/pkg/dto/errorresponse"
+	"github.com/corbado/webhook-go/pkg/logger"
+	"github.com/corbado/webhook-go/pkg/signature"
+	"github.com/corbado/webhook-go/pkg/webhookerr"
+)
+
+// ErrorFormat defines how error (non-2xx) responses are sent.
+type ErrorFormat int
+
+const (
+	// ErrorFormatJSON sends errors as JSON (see errorresponse.DTO), this is the default.
+	ErrorFormatJSON ErrorFormat = iota
+
+	// ErrorFormatText sends errors as plain text (legacy format, internal server errors have no body).
+	ErrorFormatText
+)
+
+// exchange holds the state of a single webhook request while it is handled.
+type exchange struct {
+	ctx         context.Context
+	start       time.Time
+	logger      logger.Leveled
+	errorFormat ErrorFormat
+	action      string
+	requestID   string
+	projectID   string
+	username    string
+	responseID  string
+	signature   *signature.Verification
+	clientIP    netip.Addr
+	outcome     string
+	fallback    bool
+	span        trace.Span
+	principal   *auth.Principal
+
+	// lockoutFailures is the number of authentication failures of the client address before the request
+	lockoutFailures int
+}
+
+func (c *Core) newExchange(req *Request) *exchange {
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	x := &exchange{
+		ctx:         ctx,
+		start:       time.Now(),
+		logger:      c.logger,
+		errorFormat: c.errorFormat,
+	}
+
+	x.with(logger.F("remoteAddr", req.RemoteAddr))
+
+	return x
+}
+
+// with adds given fields to the logger of the exchange and attaches the logger to the context (see
+// logger.FromContext()).
+func (x *exchange) with(fields ...logger.Field) {
+	x.logger = x.logger.With(fields...)
+	x.ctx = logger.NewContext(x.ctx, x.logger)
+}
+
+func (x *exchange) unauthorized(challenge string) *Response {
+	resp := x.fail(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+	if challenge != "" {
+		resp.Header.Set("WWW-Authenticate", challenge)
+	}
+
+	return resp
+}
+
+func (x *exchange) forbidden() *Response {
+	return x.fail(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+}
+
+func (x *exchange) badRequest(message string, args ...any) *Response {
+	return x.fail(http.StatusBadRequest, fmt.Sprintf(message, args...))
+}
+
+func (x *exchange) internalServerError(err error) *Response {
+	x.logger.Log(logger.LevelError, "Internal server error", logger.Err(err))
+
+	if x.errorFormat == ErrorFormatText {
+		return newResponse(http.StatusInternalServerError)
+	}
+
+	return x.fail(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+}
+
+// errorResponse translates given error into a response. Errors of type webhookerr.Error are sent with their
+// status code and public message, all other errors result in an internal server error.
+func (x *exchange) errorResponse(err error) *Response {
+	webhookErr, ok := webhookerr.As(err)
+	if !ok {
+		return x.internalServerError(err)
+	}
+
+	level := logger.LevelDebug
+	if webhookErr.StatusCode() >= http.StatusInternalServerError {
+		level = logger.LevelError
+	}
+
+	x.logger.Log(level, "Error response", logger.Err(err), logger.F("status", webhookErr.StatusCode()))
+
+	resp := x.fail(webhookErr.StatusCode(), webhookErr.Message())
+	if retryAfter := webhookErr.RetryAfter(); retryAfter > 0 {
+		resp.Header.Set("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+	}
+
+	return resp
+}
+
+// fail returns an error response with given status code and message in the configured error format.
+func (x *exchange) fail(statusCode int, message string) *Response {
+	if x.errorFormat == ErrorFormatText {
+		return newTextResponse(statusCode, message)
+	}
+
+	dto, err := errorresponse.New(x.responseID, x.requestID, errorresponse.CodeFromStatus(statusCode), message)
+	if err != nil {
+		x.logger.Log(logger.LevelError, "Creating error response failed", logger.Err(err))
+
+		return newTextResponse(statusCode, message)
+	}
+
+	marshaled, err := json.Marshal(dto)
+	if err != nil {
+		x.logger.Log(logger.LevelError, "Encoding error response failed", logger.Err(errors.WithStack(err)))
+
+		return newTextResponse(statusCode, message)
+	}
+
+	return newJSONResponse(statusCode, marshaled)
+}
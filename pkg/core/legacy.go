@@ -0,0 +1,66 @@
+package core
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/corbado/webhook-go/pkg/action"
+	"github.com/corbado/webhook-go/pkg/callback"
+	"github.com/corbado/webhook-go/pkg/credential"
+	"github.com/corbado/webhook-go/pkg/logger"
+)
+
+// NewLegacy returns new core instance with the built-in actions and Basic Auth with given SHA-256 hashes of
+// username and password, like the handlers were configured before the core existed (used by the deprecated
+// handler constructors).
+func NewLegacy(
+	logger logger.Logger,
+	usernameHash [32]byte,
+	passwordHash [32]byte,
+	authMethodsCallback callback.AuthMethods,
+	passwordVerifyCallback callback.PasswordVerify,
+) (*Core, error) {
+	if logger == nil {
+		return nil, errors.New("empty parameter logger")
+	}
+
+	if authMethodsCallback == nil {
+		return nil, errors.New("empty parameter authMethodsCallback")
+	}
+
+	if passwordVerifyCallback == nil {
+		return nil, errors.New("empty parameter passwordVerifyCallback")
+	}
+
+	authMethodsHandler, err := action.NewAuthMethods(callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(authMethodsCallback)))
+	if err != nil {
+		return nil, err
+	}
+
+	passwordVerifyHandler, err := action.NewPasswordVerify(callback.AdaptPasswordVerifyContext(callback.AdaptPasswordVerify(passwordVerifyCallback)))
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := credential.NewFromHashes(
+		credential.DefaultLabel,
+		hex.EncodeToString(usernameHash[:]),
+		hex.EncodeToString(passwordHash[:]),
+		time.Time{},
+		time.Time{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(&Config{
+		Logger:      logger,
+		Credentials: []*credential.Credential{cred},
+		Actions: map[string]action.Handler{
+			action.AuthMethods:    authMethodsHandler,
+			action.PasswordVerify: passwordVerifyHandler,
+		},
+	})
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Request is the transport-neutral representation of an incoming webhook request.
+type Request struct {
+	// Context is the context of the HTTP request, context.Background() is used if empty.
+	Context context.Context
+	Method  string
+	URL     string
+	Header  http.Header
+	Body    io.Reader
+
+	// RemoteAddr is the network address of the peer (IP and port, like http.Request.RemoteAddr).
+	RemoteAddr string
+}
+
+// Response is the transport-neutral representation of a webhook response.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func newResponse(statusCode int) *Response {
+	return &Response{
+		StatusCode: statusCode,
+		Header:     http.Header{},
+	}
+}
+
+func newTextResponse(statusCode int, text string) *Response {
+	resp := newResponse(statusCode)
+	resp.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	resp.Body = []byte(text)
+
+	return resp
+}
+
+func newJSONResponse(statusCode int, body []byte) *Response {
+	resp := newResponse(statusCode)
+	resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp.Body = body
+
+	return resp
+}
+
+// Send writes the response (headers, status code and body) to the given response writer.
+func (r *Response) Send(w http.ResponseWriter) error {
+	for name, values := range r.Header {
+		w.Header()[name] = values
+	}
+
+	w.WriteHeader(r.StatusCode)
+
+	if len(r.Body) == 0 {
+		return nil
+	}
+
+	if _, err := w.Write(r.Body); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
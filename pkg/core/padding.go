@@ -0,0 +1,42 @@
+package core
+
+import (
+	"math/rand"
+	"time"
+)
+
+// MinDuration defines the minimum duration of responses of an action, faster responses are delayed so the
+// response time does not leak information (whether a user exists for example).
+type MinDuration struct {
+	Duration time.Duration
+
+	// Jitter is optional, a random duration between zero and Jitter is added to Duration.
+	Jitter time.Duration
+}
+
+// pad delays the response until the minimum duration of the action (measured from the start of the request)
+// has passed. Padding stops early if the context of the request is done.
+func (c *Core) pad(x *exchange) {
+	minDuration, exists := c.minDurations[x.action]
+	if !exists {
+		return
+	}
+
+	duration := minDuration.Duration
+	if minDuration.Jitter > 0 {
+		duration += time.Duration(rand.Int63n(int64(minDuration.Jitter) + 1))
+	}
+
+	remaining := duration - time.Since(x.start)
+	if remaining <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-x.ctx.Done():
+	}
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/corbado/webhook-go/pkg/action"
+)
+
+// recovered converts the given recovered panic value of given action into an error (with stack trace) and
+// reports it through the logger (done by the caller) and the configured hooks.
+func (c *Core) recovered(ctx context.Context, actionName string, value any) error {
+	var err error
+	if valueErr, ok := value.(error); ok {
+		err = errors.Wrap(valueErr, "recovered from panic")
+	} else {
+		err = errors.Errorf("recovered from panic: %v", value)
+	}
+
+	for _, h := range c.hooks {
+		if h.OnPanic != nil {
+			h.OnPanic(ctx, actionName, err)
+		}
+	}
+
+	return err
+}
+
+// handleSafely executes given handler of given action and converts a panic into an error. The configured hooks
+// are notified when the handler returned. It does not access the exchange, so it can be abandoned after a
+// timeout (see handleWithTimeout()).
+func (c *Core) handleSafely(ctx context.Context, actionName string, handler action.Handler, req any) (resp any, err error) {
+	start := time.Now()
+
+	defer func() {
+		if value := recover(); value != nil {
+			resp, err = nil, c.recovered(ctx, actionName, value)
+		}
+
+		for _, h := range c.hooks {
+			if h.OnHandled != nil {
+				h.OnHandled(ctx, actionName, time.Since(start), err)
+			}
+		}
+	}()
+
+	return handler.Handle(ctx, req)
+}
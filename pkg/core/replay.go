@@ -0,0 +1,63 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/corbado/webhook-go/pkg/logger"
+	"github.com/corbado/webhook-go/pkg/replay"
+)
+
+// OutcomeReplayed is the outcome of requests which were answered with the remembered response of an earlier
+// request with the same id (see replay.ModeReplay).
+const OutcomeReplayed = "replayed"
+
+// handleGuarded executes given dispatch function only if the request id was not seen before. Successful
+// responses are remembered (to be replayed in replay.ModeReplay), after failed requests and fallback
+// responses (see Timeout) the id is released so the request can be retried.
+func (c *Core) handleGuarded(x *exchange, dispatch func() *Response) *Response {
+	key := x.projectID + "/" + x.requestID
+
+	entry, reserved, err := c.replayGuard.Reserve(x.ctx, key)
+	if err != nil {
+		return x.errorResponse(err)
+	}
+
+	if !reserved {
+		if c.replayGuard.Mode() == replay.ModeReplay && entry != nil && entry.Done {
+			x.logger.Log(logger.LevelInfo, "Replaying response of duplicate request")
+			x.outcome = OutcomeReplayed
+
+			resp := newResponse(entry.StatusCode)
+			for name, values := range entry.Header {
+				resp.Header[name] = append([]string(nil), values...)
+			}
+
+			resp.Body = entry.Body
+
+			return resp
+		}
+
+		x.logger.Log(logger.LevelWarn, "Rejecting duplicate request")
+
+		return x.fail(http.StatusConflict, "Duplicate request")
+	}
+
+	resp := dispatch()
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices && !x.fallback {
+		err = c.replayGuard.Complete(x.ctx, key, &replay.Entry{
+			Done:       true,
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       resp.Body,
+		})
+	} else {
+		err = c.replayGuard.Release(x.ctx, key)
+	}
+
+	if err != nil {
+		x.logger.Log(logger.LevelError, "Updating replay guard failed", logger.Err(err))
+	}
+
+	return resp
+}
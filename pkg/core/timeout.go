@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/corbado/webhook-go/pkg/action"
+	"github.com/corbado/webhook-go/pkg/logger"
+)
+
+// Timeout defines the deadline of an action handler and the fallback which is used if it passes.
+type Timeout struct {
+	Duration time.Duration
+
+	// Fallback is optional, action.NewDefaultFallback() is used if empty.
+	Fallback action.Fallback
+}
+
+type handleResult struct {
+	resp any
+	err  error
+}
+
+// handleWithTimeout executes given handler, if it does not finish within given timeout the fallback is used
+// and the handler is abandoned (it keeps running in the background until it returns).
+func (c *Core) handleWithTimeout(x *exchange, handler action.Handler, req any, timeout *Timeout) (any, error) {
+	ctx, cancel := context.WithTimeout(x.ctx, timeout.Duration)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan handleResult, 1)
+
+	// the exchange is not shared with the handler goroutine as it keeps running after a timeout while the
+	// exchange is still used (and changed) by the request
+	actionName := x.action
+
+	go func() {
+		resp, err := c.handleSafely(ctx, actionName, handler, req)
+		done <- handleResult{resp: resp, err: err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.resp, result.err
+
+	case <-ctx.Done():
+		x.logger.Log(logger.LevelError, "Handler did not finish in time, using fallback",
+			logger.Err(errors.WithStack(ctx.Err())),
+			logger.F("timeout", timeout.Duration.String()),
+		)
+
+		go func(l logger.Leveled) {
+			result := <-done
+
+			fields := []logger.Field{logger.F("duration", time.Since(start).String())}
+			if result.err != nil {
+				fields = append(fields, logger.F("error", result.err.Error()))
+			}
+
+			l.Log(logger.LevelWarn, "Abandoned handler finished", fields...)
+		}(x.logger)
+
+		x.fallback = true
+
+		fallback := timeout.Fallback
+		if fallback == nil {
+			fallback = action.NewDefaultFallback()
+		}
+
+		return fallback(x.ctx, req)
+	}
+}
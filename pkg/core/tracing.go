@@ -0,0 +1,76 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer which creates the spans of the webhook.
+const TracerName = "github.com/corbado/webhook-go"
+
+// startRequestSpan extracts the trace context from the headers of the request and starts the server span of
+// the request. The span is ended by finish().
+func (c *Core) startRequestSpan(x *exchange, req *Request) {
+	ctx := x.ctx
+	if req.Header != nil {
+		ctx = c.propagator.Extract(ctx, propagation.HeaderCarrier(req.Header))
+	}
+
+	x.ctx, x.span = c.tracer.Start(ctx, "webhook",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("http.request.method", req.Method)),
+	)
+}
+
+// endRequestSpan adds the attributes of the handled request to the server span and ends it. Usernames and
+// passwords are never added.
+func (c *Core) endRequestSpan(x *exchange, resp *Response, outcome string) {
+	x.span.SetAttributes(
+		attribute.String("corbado.action", x.action),
+		attribute.String("corbado.project_id", x.projectID),
+		attribute.String("corbado.request_id", x.requestID),
+		attribute.String("corbado.response_id", x.responseID),
+		attribute.String("corbado.outcome", outcome),
+		attribute.Int("http.response.status_code", resp.StatusCode),
+	)
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		x.span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+
+	x.span.End()
+}
+
+// startSpan starts a child span with given name, the context of the exchange carries the child span until
+// the returned function is called (with the error of the traced operation, if any) to end it.
+func (c *Core) startSpan(x *exchange, name string) func(err error) {
+	parent := trace.SpanFromContext(x.ctx)
+
+	var span trace.Span
+	x.ctx, span = c.tracer.Start(x.ctx, name)
+
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		span.End()
+
+		x.ctx = trace.ContextWithSpan(x.ctx, parent)
+	}
+}
+
+// responseError returns an error describing given error response (nil if there is no response).
+func responseError(resp *Response) error {
+	if resp == nil {
+		return nil
+	}
+
+	return errors.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+}
@@ -0,0 +1,124 @@
+package credential
+
+import (
+	"crypto/sha256"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultLabel is the label of the credential configured with username and password (instead of credentials).
+const DefaultLabel = "default"
+
+// Credential is a Basic Auth username/password pair which is accepted by the webhook, optionally limited to a
+// time window. Multiple credentials can be configured to rotate the webhook password without downtime.
+type Credential struct {
+	label     string
+	username  verifier
+	password  verifier
+	notBefore time.Time
+	notAfter  time.Time
+}
+
+// New returns new credential with given label (shows up in log output when the credential is used), username
+// and password. The credential is only accepted after notBefore and before notAfter, zero values disable the
+// respective limit.
+func New(label string, username string, password string, notBefore time.Time, notAfter time.Time) (*Credential, error) {
+	if username == "" {
+		return nil, errors.New("empty parameter username")
+	}
+
+	if password == "" {
+		return nil, errors.New("empty parameter password")
+	}
+
+	usernameHash := sha256.Sum256([]byte(username))
+	passwordHash := sha256.Sum256([]byte(password))
+
+	return newCredential(label, sha256Verifier(usernameHash), sha256Verifier(passwordHash), notBefore, notAfter)
+}
+
+// NewFromHashes returns new credential like New() but with pre-computed hashes of username and password, so
+// the plaintext is not needed. Supported are hex encoded SHA-256 hashes (see HashSHA256()), bcrypt hashes (see
+// HashBcrypt()) and argon2id hashes in PHC string format (see HashArgon2id()). Results of bcrypt and argon2id
+// verifications are cached.
+func NewFromHashes(label string, usernameHash string, passwordHash string, notBefore time.Time, notAfter time.Time) (*Credential, error) {
+	if usernameHash == "" {
+		return nil, errors.New("empty parameter usernameHash")
+	}
+
+	if passwordHash == "" {
+		return nil, errors.New("empty parameter passwordHash")
+	}
+
+	usernameVerifier, err := newVerifierFromHash(usernameHash)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid parameter usernameHash")
+	}
+
+	passwordVerifier, err := newVerifierFromHash(passwordHash)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid parameter passwordHash")
+	}
+
+	return newCredential(label, usernameVerifier, passwordVerifier, notBefore, notAfter)
+}
+
+func newCredential(label string, username verifier, password verifier, notBefore time.Time, notAfter time.Time) (*Credential, error) {
+	if label == "" {
+		return nil, errors.New("empty parameter label")
+	}
+
+	if !notBefore.IsZero() && !notAfter.IsZero() && !notAfter.After(notBefore) {
+		return nil, errors.New("notAfter must be after notBefore")
+	}
+
+	return &Credential{
+		label:     label,
+		username:  username,
+		password:  password,
+		notBefore: notBefore,
+		notAfter:  notAfter,
+	}, nil
+}
+
+// Label returns the label of the credential.
+func (c *Credential) Label() string {
+	return c.label
+}
+
+// Verify returns true if given username and password match the credential (compared in constant time). Username
+// and password are always both verified to not leak through timing whether the username matched.
+func (c *Credential) Verify(username string, password string) bool {
+	usernameMatch := c.username.verify(username)
+	passwordMatch := c.password.verify(password)
+
+	return usernameMatch && passwordMatch
+}
+
+// ValidAt returns true if the credential is accepted at given time.
+func (c *Credential) ValidAt(now time.Time) bool {
+	if !c.notBefore.IsZero() && now.Before(c.notBefore) {
+		return false
+	}
+
+	if !c.notAfter.IsZero() && !now.Before(c.notAfter) {
+		return false
+	}
+
+	return true
+}
+
+// Match returns the first of given credentials which is valid at given time and matches given username and
+// password. All credentials are verified (also after a match) to not leak which one matched through timing.
+func Match(credentials []*Credential, username string, password string, now time.Time) (*Credential, bool) {
+	var matched *Credential
+
+	for _, credential := range credentials {
+		if credential.Verify(username, password) && credential.ValidAt(now) && matched == nil {
+			matched = credential
+		}
+	}
+
+	return matched, matched != nil
+}
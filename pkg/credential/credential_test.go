@@ -0,0 +1,182 @@
+package credential_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/corbado/webhook-go/pkg/credential"
+)
+
+func TestNew(t *testing.T) {
+	c, err := credential.New("", "username", "password", time.Time{}, time.Time{})
+	assert.ErrorContains(t, err, "empty parameter label")
+	assert.Nil(t, c)
+
+	now := time.Now()
+	c, err = credential.New("old", "username", "password", now, now.Add(-time.Hour))
+	assert.ErrorContains(t, err, "notAfter must be after notBefore")
+	assert.Nil(t, c)
+
+	c, err = credential.New("old", "username", "password", time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, "old", c.Label())
+	assert.True(t, c.Verify("username", "password"))
+	assert.False(t, c.Verify("username", "invalid"))
+	assert.False(t, c.Verify("invalid", "password"))
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	old, err := credential.New("old", "username", "oldPassword", time.Time{}, now.Add(time.Hour))
+	require.NoError(t, err)
+
+	current, err := credential.New("new", "username", "newPassword", now.Add(-time.Hour), time.Time{})
+	require.NoError(t, err)
+
+	future, err := credential.New("future", "username", "futurePassword", now.Add(time.Hour), time.Time{})
+	require.NoError(t, err)
+
+	credentials := []*credential.Credential{old, current, future}
+
+	matched, ok := credential.Match(credentials, "username", "oldPassword", now)
+	assert.True(t, ok)
+	assert.Equal(t, "old", matched.Label())
+
+	matched, ok = credential.Match(credentials, "username", "newPassword", now)
+	assert.True(t, ok)
+	assert.Equal(t, "new", matched.Label())
+
+	_, ok = credential.Match(credentials, "username", "futurePassword", now)
+	assert.False(t, ok)
+
+	_, ok = credential.Match(credentials, "username", "oldPassword", now.Add(2*time.Hour))
+	assert.False(t, ok)
+
+	matched, ok = credential.Match(credentials, "username", "futurePassword", now.Add(2*time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, "future", matched.Label())
+}
+
+func TestNewFromHashes(t *testing.T) {
+	c, err := credential.NewFromHashes("hashed", "invalid", credential.HashSHA256("password"), time.Time{}, time.Time{})
+	assert.ErrorContains(t, err, "invalid parameter usernameHash: unsupported hash")
+	assert.Nil(t, c)
+
+	c, err = credential.NewFromHashes("hashed", credential.HashSHA256("username"), "$argon2id$v=19$invalid", time.Time{}, time.Time{})
+	assert.ErrorContains(t, err, "invalid parameter passwordHash: invalid argon2id hash")
+	assert.Nil(t, c)
+
+	salt := "c29tZXNhbHRzb21lc2FsdA"
+	key := "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+
+	for hash, wantErr := range map[string]string{
+		"$argon2id$v=19$m=65536,t=0,p=4$" + salt + "$" + key:    "time (t=0) must be at least 1",
+		"$argon2id$v=19$m=65536,t=3,p=0$" + salt + "$" + key:    "parallelism (p=0) must be at least 1",
+		"$argon2id$v=19$m=16,t=3,p=4$" + salt + "$" + key:       "memory (m=16) must be at least 8 times the parallelism (p=4)",
+		"$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$" + key:          "salt must be at least 8 bytes",
+		"$argon2id$v=19$m=65536,t=3,p=4$" + salt + "$a2V5":      "key must be at least 4 bytes",
+		"$argon2id$v=19$m=65536,t=3,p=4$" + salt + "$":          "key must be at least 4 bytes",
+		"$argon2id$v=19$m=65536,t=3,p=1000$" + salt + "$" + key: "invalid argon2id hash parameters",
+	} {
+		c, err = credential.NewFromHashes("hashed", credential.HashSHA256("username"), hash, time.Time{}, time.Time{})
+		assert.ErrorContains(t, err, wantErr, hash)
+		assert.Nil(t, c)
+	}
+
+	bcryptHash, err := credential.HashBcrypt("password", bcrypt.MinCost)
+	require.NoError(t, err)
+
+	argon2idHash, err := credential.HashArgon2id("password")
+	require.NoError(t, err)
+	assert.Regexp(t, `^\$argon2id\$v=19\$m=65536,t=3,p=4\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`, argon2idHash)
+
+	for _, passwordHash := range []string{credential.HashSHA256("password"), bcryptHash, argon2idHash} {
+		c, err = credential.NewFromHashes("hashed", credential.HashSHA256("username"), passwordHash, time.Time{}, time.Time{})
+		require.NoError(t, err)
+
+		// verify twice to also verify cached results
+		for i := 0; i < 2; i++ {
+			assert.True(t, c.Verify("username", "password"))
+			assert.False(t, c.Verify("username", "invalid"))
+			assert.False(t, c.Verify("invalid", "password"))
+		}
+	}
+}
+
+func TestHashSHA256(t *testing.T) {
+	assert.Equal(t, "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8", credential.HashSHA256("password"))
+}
+
+func TestCachedVerifier(t *testing.T) {
+	counting := &credential.CountingVerifier{
+		Accept: func(secret string) bool {
+			return strings.HasPrefix(secret, "valid")
+		},
+	}
+	verify := credential.NewCachedVerifier(counting)
+
+	assert.True(t, verify("valid"))
+	assert.True(t, verify("valid"))
+	assert.Equal(t, 1, counting.Calls)
+
+	// failed verifications are not cached and do not evict successful ones
+	for i := 0; i < 2*credential.MaxCachedResults; i++ {
+		assert.False(t, verify(fmt.Sprintf("invalid-%d", i)))
+	}
+
+	assert.False(t, verify("invalid-0"))
+	assert.Equal(t, 2+2*credential.MaxCachedResults, counting.Calls)
+
+	assert.True(t, verify("valid"))
+	assert.Equal(t, 2+2*credential.MaxCachedResults, counting.Calls)
+
+	// the least recently used entry is evicted ("valid" was used last, so "valid-0" is evicted)
+	for i := 0; i < credential.MaxCachedResults-1; i++ {
+		assert.True(t, verify(fmt.Sprintf("valid-%d", i)))
+	}
+
+	assert.True(t, verify("valid"))
+	assert.True(t, verify("valid-new"))
+
+	calls := counting.Calls
+
+	assert.True(t, verify("valid"))
+	assert.True(t, verify("valid-1"))
+	assert.Equal(t, calls, counting.Calls)
+
+	assert.True(t, verify("valid-0"))
+	assert.Equal(t, calls+1, counting.Calls)
+}
+
+func TestVerifyChecksUsernameAndPassword(t *testing.T) {
+	username := &credential.CountingVerifier{
+		Accept: func(secret string) bool {
+			return secret == "username"
+		},
+	}
+	password := &credential.CountingVerifier{
+		Accept: func(secret string) bool {
+			return secret == "password"
+		},
+	}
+
+	c, err := credential.NewWithVerifiers(username, password)
+	require.NoError(t, err)
+
+	// the password is also verified for a wrong username to not leak the username match through timing
+	assert.False(t, c.Verify("invalid", "password"))
+	assert.Equal(t, 1, username.Calls)
+	assert.Equal(t, 1, password.Calls)
+
+	assert.False(t, c.Verify("username", "invalid"))
+	assert.True(t, c.Verify("username", "password"))
+	assert.Equal(t, 3, username.Calls)
+	assert.Equal(t, 3, password.Calls)
+}
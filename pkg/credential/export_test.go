@@ -0,0 +1,28 @@
+package credential
+
+import "time"
+
+// MaxCachedResults exposes maxCachedResults to tests.
+const MaxCachedResults = maxCachedResults
+
+// CountingVerifier accepts secrets for which Accept returns true and counts its verifications.
+type CountingVerifier struct {
+	Accept func(secret string) bool
+	Calls  int
+}
+
+func (c *CountingVerifier) verify(secret string) bool {
+	c.Calls++
+
+	return c.Accept(secret)
+}
+
+// NewCachedVerifier exposes the verify function of a cached verifier to tests.
+func NewCachedVerifier(verifier *CountingVerifier) func(secret string) bool {
+	return newCachedVerifier(verifier).verify
+}
+
+// NewWithVerifiers returns new credential with given username and password verifiers.
+func NewWithVerifiers(username *CountingVerifier, password *CountingVerifier) (*Credential, error) {
+	return newCredential(DefaultLabel, username, password, time.Time{}, time.Time{})
+}
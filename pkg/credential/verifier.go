@@ -0,0 +1,245 @@
+package credential
+
+import (
+	"container/list"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxCachedResults limits the number of cached successful verifications per slow hash.
+const maxCachedResults = 128
+
+// Default parameters used by HashArgon2id() (see RFC 9106, second recommended option).
+const (
+	argon2idTime    = 3
+	argon2idMemory  = 64 * 1024
+	argon2idThreads = 4
+	argon2idSaltLen = 16
+	argon2idKeyLen  = 32
+)
+
+// Bounds of the parameters of argon2id hashes given to NewFromHashes() (see RFC 9106, section 3.1).
+const (
+	argon2idMinSaltLen = 8
+	argon2idMinKeyLen  = 4
+)
+
+type verifier interface {
+	verify(secret string) bool
+}
+
+// sha256Verifier verifies secrets against a SHA-256 hash.
+type sha256Verifier [32]byte
+
+func (s sha256Verifier) verify(secret string) bool {
+	hash := sha256.Sum256([]byte(secret))
+
+	return subtle.ConstantTimeCompare(s[:], hash[:]) == 1
+}
+
+// bcryptVerifier verifies secrets against a bcrypt hash.
+type bcryptVerifier []byte
+
+func (b bcryptVerifier) verify(secret string) bool {
+	return bcrypt.CompareHashAndPassword(b, []byte(secret)) == nil
+}
+
+// argon2idVerifier verifies secrets against an argon2id hash.
+type argon2idVerifier struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func (a *argon2idVerifier) verify(secret string) bool {
+	key := argon2.IDKey([]byte(secret), a.salt, a.time, a.memory, a.threads, uint32(len(a.key)))
+
+	return subtle.ConstantTimeCompare(a.key, key) == 1
+}
+
+// cachedVerifier caches successful verifications of a (slow) verifier, keyed by the SHA-256 hash of the
+// secret. Failed verifications are not cached, otherwise requests with random secrets would evict the valid
+// secret. The least recently used entry is evicted when the cache is full.
+type cachedVerifier struct {
+	verifier verifier
+	mutex    sync.Mutex
+	entries  map[[32]byte]*list.Element
+	order    *list.List
+}
+
+func newCachedVerifier(verifier verifier) *cachedVerifier {
+	return &cachedVerifier{
+		verifier: verifier,
+		entries:  make(map[[32]byte]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *cachedVerifier) verify(secret string) bool {
+	key := sha256.Sum256([]byte(secret))
+
+	c.mutex.Lock()
+	if element, exists := c.entries[key]; exists {
+		c.order.MoveToFront(element)
+		c.mutex.Unlock()
+
+		return true
+	}
+	c.mutex.Unlock()
+
+	if !c.verifier.verify(secret) {
+		return false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, exists := c.entries[key]; !exists {
+		c.entries[key] = c.order.PushFront(key)
+
+		if c.order.Len() > maxCachedResults {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.([32]byte))
+		}
+	}
+
+	return true
+}
+
+// newVerifierFromHash returns verifier for given hash string, supported are hex encoded SHA-256 hashes,
+// bcrypt hashes and argon2id hashes (PHC string format).
+func newVerifierFromHash(hash string) (verifier, error) {
+	switch {
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, errors.Wrap(err, "invalid bcrypt hash")
+		}
+
+		return newCachedVerifier(bcryptVerifier(hash)), nil
+
+	case strings.HasPrefix(hash, "$argon2id$"):
+		a, err := parseArgon2id(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		return newCachedVerifier(a), nil
+
+	default:
+		decoded, err := hex.DecodeString(hash)
+		if err != nil || len(decoded) != sha256.Size {
+			return nil, errors.New("unsupported hash, must be hex encoded SHA-256, bcrypt or argon2id hash")
+		}
+
+		var s sha256Verifier
+		copy(s[:], decoded)
+
+		return s, nil
+	}
+}
+
+func parseArgon2id(hash string) (*argon2idVerifier, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return nil, errors.New("invalid argon2id hash, expected format $argon2id$v=19$m=...,t=...,p=...$salt$key")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, errors.Wrap(err, "invalid argon2id hash version")
+	}
+
+	if version != argon2.Version {
+		return nil, errors.Errorf("unsupported argon2id hash version %d", version)
+	}
+
+	a := &argon2idVerifier{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &a.memory, &a.time, &a.threads); err != nil {
+		return nil, errors.Wrap(err, "invalid argon2id hash parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid argon2id hash salt")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid argon2id hash key")
+	}
+
+	if a.time < 1 {
+		return nil, errors.Errorf("invalid argon2id hash, time (t=%d) must be at least 1", a.time)
+	}
+
+	if a.threads < 1 {
+		return nil, errors.Errorf("invalid argon2id hash, parallelism (p=%d) must be at least 1", a.threads)
+	}
+
+	if a.memory < 8*uint32(a.threads) {
+		return nil, errors.Errorf("invalid argon2id hash, memory (m=%d) must be at least 8 times the parallelism (p=%d)", a.memory, a.threads)
+	}
+
+	if len(salt) < argon2idMinSaltLen {
+		return nil, errors.Errorf("invalid argon2id hash, salt must be at least %d bytes", argon2idMinSaltLen)
+	}
+
+	if len(key) < argon2idMinKeyLen {
+		return nil, errors.Errorf("invalid argon2id hash, key must be at least %d bytes", argon2idMinKeyLen)
+	}
+
+	a.salt = salt
+	a.key = key
+
+	return a, nil
+}
+
+// HashSHA256 returns the hex encoded SHA-256 hash of given secret (see NewFromHashes()).
+func HashSHA256(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// HashBcrypt returns the bcrypt hash of given secret with given cost (see NewFromHashes()).
+func HashBcrypt(secret string, cost int) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), cost)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return string(hash), nil
+}
+
+// HashArgon2id returns the argon2id hash of given secret in PHC string format (see NewFromHashes()).
+func HashArgon2id(secret string) (string, error) {
+	salt := make([]byte, argon2idSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	key := argon2.IDKey([]byte(secret), salt, argon2idTime, argon2idMemory, argon2idThreads, argon2idKeyLen)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		argon2idMemory,
+		argon2idTime,
+		argon2idThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
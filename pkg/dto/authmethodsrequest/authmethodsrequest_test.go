@@ -1,8 +1,11 @@
 package authmethodsrequest_test
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,7 +19,7 @@ func TestNewFromBody(t *testing.T) {
 	assert.Nil(t, dto)
 
 	dto, err = authmethodsrequest.NewFromBody(readTestDataJSON("broken"))
-	assert.ErrorContains(t, err, "invalid character")
+	assert.ErrorContains(t, err, "invalid JSON syntax at offset")
 	assert.Nil(t, dto)
 
 	dto, err = authmethodsrequest.NewFromBody(readTestDataJSON("invalid"))
@@ -43,3 +46,17 @@ func readTestDataJSON(name string) []byte {
 
 	return data
 }
+
+func TestNewFromReader(t *testing.T) {
+	dto, err := authmethodsrequest.NewFromReader(strings.NewReader(""))
+	assert.ErrorContains(t, err, "EOF")
+	assert.Nil(t, dto)
+
+	dto, err = authmethodsrequest.NewFromReader(io.MultiReader(bytes.NewReader(readTestDataJSON("valid")), strings.NewReader("{}")))
+	assert.ErrorContains(t, err, "unexpected data after JSON object")
+	assert.Nil(t, dto)
+
+	dto, err = authmethodsrequest.NewFromReader(bytes.NewReader(readTestDataJSON("valid")))
+	assert.NoError(t, err)
+	assert.Equal(t, "who-1234567890", dto.ID)
+}
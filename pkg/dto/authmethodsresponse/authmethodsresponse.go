@@ -33,3 +33,13 @@ func New(responseID string, status Status) (*DTO, error) {
 		},
 	}, nil
 }
+
+// GetResponseID returns the responseID of the response.
+func (d *DTO) GetResponseID() string {
+	return d.ResponseID
+}
+
+// Outcome returns the status of the response ("exists" or "not_exists").
+func (d *DTO) Outcome() string {
+	return d.Data.Status
+}
@@ -17,4 +17,5 @@ func TestNew(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "d5a80602-a771-4532-8cc8-6d4a9003d92a", dto.ResponseID)
 	assert.Equal(t, "exists", dto.Data.Status)
+	assert.Equal(t, "exists", dto.Outcome())
 }
@@ -0,0 +1,59 @@
+package errorresponse
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type DTO struct {
+	ResponseID string    `json:"responseID"`
+	RequestID  string    `json:"requestID"`
+	Error      *DTOError `json:"error"`
+}
+
+type DTOError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// New returns new error response DTO. The responseID is the same as in successful responses, the requestID
+// is the id of the request (if it could be decoded) and code is a machine-readable error code (see
+// CodeFromStatus()).
+func New(responseID string, requestID string, code string, message string) (*DTO, error) {
+	if code == "" {
+		return nil, errors.New("code must not be empty")
+	}
+
+	return &DTO{
+		ResponseID: responseID,
+		RequestID:  requestID,
+		Error: &DTOError{
+			Code:    code,
+			Message: message,
+		},
+	}, nil
+}
+
+// CodeFromStatus returns the error code for given HTTP status code (status text in snake case, for example
+// 'bad_request' for status code 400).
+func CodeFromStatus(statusCode int) string {
+	text := http.StatusText(statusCode)
+	if text == "" {
+		return "unknown_error"
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		case r == ' ' || r == '-':
+			return '_'
+		case r >= 'a' && r <= 'z':
+			return r
+		default:
+			return -1
+		}
+	}, text)
+}
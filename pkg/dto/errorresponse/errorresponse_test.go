@@ -0,0 +1,31 @@
+package errorresponse_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/corbado/webhook-go/pkg/dto/errorresponse"
+)
+
+func TestNew(t *testing.T) {
+	dto, err := errorresponse.New("", "", "", "message")
+	assert.ErrorContains(t, err, "code must not be empty")
+	assert.Nil(t, dto)
+
+	dto, err = errorresponse.New("d5a80602-a771-4532-8cc8-6d4a9003d92a", "who-1234567890", "bad_request", "username must not be empty")
+	assert.NoError(t, err)
+	assert.Equal(t, "d5a80602-a771-4532-8cc8-6d4a9003d92a", dto.ResponseID)
+	assert.Equal(t, "who-1234567890", dto.RequestID)
+	assert.Equal(t, "bad_request", dto.Error.Code)
+	assert.Equal(t, "username must not be empty", dto.Error.Message)
+}
+
+func TestCodeFromStatus(t *testing.T) {
+	assert.Equal(t, "bad_request", errorresponse.CodeFromStatus(http.StatusBadRequest))
+	assert.Equal(t, "internal_server_error", errorresponse.CodeFromStatus(http.StatusInternalServerError))
+	assert.Equal(t, "request_entity_too_large", errorresponse.CodeFromStatus(http.StatusRequestEntityTooLarge))
+	assert.Equal(t, "im_a_teapot", errorresponse.CodeFromStatus(http.StatusTeapot))
+	assert.Equal(t, "unknown_error", errorresponse.CodeFromStatus(599))
+}
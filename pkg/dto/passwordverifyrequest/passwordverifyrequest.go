@@ -1,10 +1,14 @@
 package passwordverifyrequest
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
 	"strings"
 
 	"github.com/pkg/errors"
+
+	"github.com/corbado/webhook-go/pkg/redact"
 )
 
 type DTO struct {
@@ -16,7 +20,9 @@ type DTO struct {
 
 type DTOData struct {
 	Username string `json:"username"`
-	Password string `json:"password"`
+	// Password is masked when formatted or marshaled (to not leak it into log output), use
+	// Password.Value() to get the plaintext.
+	Password redact.Secret `json:"password"`
 }
 
 // NewFromBody returns new request DTO for 'passwordVerify' action from given body.
@@ -25,11 +31,27 @@ func NewFromBody(body []byte) (*DTO, error) {
 		return nil, errors.New("passed empty body")
 	}
 
+	return NewFromReader(bytes.NewReader(body))
+}
+
+// NewFromReader returns new request DTO for 'passwordVerify' action, the body is decoded while reading from
+// given reader.
+func NewFromReader(body io.Reader) (*DTO, error) {
 	dto := &DTO{
 		Data: &DTOData{},
 	}
-	if err := json.Unmarshal(body, dto); err != nil {
-		return nil, errors.Wrap(err, "json.Unmarshal() failed")
+
+	decoder := json.NewDecoder(body)
+	if err := decoder.Decode(dto); err != nil {
+		return nil, errors.Wrap(redact.JSONError(err), "decoder.Decode() failed")
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return nil, errors.Wrap(redact.JSONError(err), "decoder.Token() failed")
+		}
+
+		return nil, errors.New("unexpected data after JSON object")
 	}
 
 	validationErrors := make([]string, 0, 5)
@@ -59,3 +81,18 @@ func NewFromBody(body []byte) (*DTO, error) {
 
 	return dto, nil
 }
+
+// GetID returns the id of the request.
+func (d *DTO) GetID() string {
+	return d.ID
+}
+
+// GetProjectID returns the project id of the request.
+func (d *DTO) GetProjectID() string {
+	return d.ProjectID
+}
+
+// GetUsername returns the username of the request.
+func (d *DTO) GetUsername() string {
+	return d.Data.Username
+}
@@ -1,8 +1,11 @@
 package passwordverifyrequest_test
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,7 +19,7 @@ func TestNewFromBody(t *testing.T) {
 	assert.Nil(t, dto)
 
 	dto, err = passwordverifyrequest.NewFromBody(readTestDataJSON("broken"))
-	assert.ErrorContains(t, err, "invalid character")
+	assert.ErrorContains(t, err, "invalid JSON syntax at offset")
 	assert.Nil(t, dto)
 
 	dto, err = passwordverifyrequest.NewFromBody(readTestDataJSON("invalid"))
@@ -30,7 +33,8 @@ func TestNewFromBody(t *testing.T) {
 	assert.Equal(t, "pro-1234567890", dto.ProjectID)
 	assert.Equal(t, "passwordVerify", dto.Action)
 	assert.Equal(t, "testUsername", dto.Data.Username)
-	assert.Equal(t, "testPassword", dto.Data.Password)
+	assert.Equal(t, "testPassword", dto.Data.Password.Value())
+	assert.Equal(t, "&{Username:testUsername Password:[REDACTED]}", fmt.Sprintf("%+v", dto.Data))
 }
 
 func readTestDataJSON(name string) []byte {
@@ -45,3 +49,17 @@ func readTestDataJSON(name string) []byte {
 
 	return data
 }
+
+func TestNewFromReader(t *testing.T) {
+	dto, err := passwordverifyrequest.NewFromReader(strings.NewReader(""))
+	assert.ErrorContains(t, err, "EOF")
+	assert.Nil(t, dto)
+
+	dto, err = passwordverifyrequest.NewFromReader(io.MultiReader(bytes.NewReader(readTestDataJSON("valid")), strings.NewReader("{}")))
+	assert.ErrorContains(t, err, "unexpected data after JSON object")
+	assert.Nil(t, dto)
+
+	dto, err = passwordverifyrequest.NewFromReader(bytes.NewReader(readTestDataJSON("valid")))
+	assert.NoError(t, err)
+	assert.Equal(t, "who-1234567890", dto.ID)
+}
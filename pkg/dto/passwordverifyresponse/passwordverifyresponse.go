@@ -20,3 +20,17 @@ func New(responseID string, success bool) (*DTO, error) {
 		},
 	}, nil
 }
+
+// GetResponseID returns the responseID of the response.
+func (d *DTO) GetResponseID() string {
+	return d.ResponseID
+}
+
+// Outcome returns "success" or "failure" depending on the result of the password verification.
+func (d *DTO) Outcome() string {
+	if d.Data.Success {
+		return "success"
+	}
+
+	return "failure"
+}
@@ -13,4 +13,5 @@ func TestNew(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "d5a80602-a771-4532-8cc8-6d4a9003d92a", dto.ResponseID)
 	assert.True(t, dto.Data.Success)
+	assert.Equal(t, "success", dto.Outcome())
 }
@@ -1,31 +1,22 @@
 package ginhandler
 
 import (
-	"crypto/sha256"
-	"crypto/subtle"
-	"fmt"
-	"net/http"
-
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 
 	"github.com/corbado/webhook-go/pkg/callback"
-	"github.com/corbado/webhook-go/pkg/dto/authmethodsrequest"
-	"github.com/corbado/webhook-go/pkg/dto/authmethodsresponse"
-	"github.com/corbado/webhook-go/pkg/dto/passwordverifyrequest"
-	"github.com/corbado/webhook-go/pkg/dto/passwordverifyresponse"
+	"github.com/corbado/webhook-go/pkg/core"
 	"github.com/corbado/webhook-go/pkg/logger"
 )
 
 type GinHandler struct {
-	logger                 logger.Logger
-	usernameHash           [32]byte
-	passwordHash           [32]byte
-	authMethodsCallback    callback.AuthMethods
-	passwordVerifyCallback callback.PasswordVerify
+	core *core.Core
 }
 
-// New returns Gin handler which can be used in Gin Web Framework.
+// New returns Gin handler which can be used in Gin Web Framework, requests are authenticated with Basic Auth
+// against given SHA-256 hashes of username and password.
+//
+// Deprecated: Use corbado.NewBuilder() (or NewFromCore() with a custom core), which support all features.
 func New(
 	logger logger.Logger,
 	usernameHash [32]byte,
@@ -33,153 +24,38 @@ func New(
 	authMethodsCallback callback.AuthMethods,
 	passwordVerifyCallback callback.PasswordVerify,
 ) (*GinHandler, error) {
-	if logger == nil {
-		return nil, errors.New("empty parameter logger")
+	c, err := core.NewLegacy(logger, usernameHash, passwordHash, authMethodsCallback, passwordVerifyCallback)
+	if err != nil {
+		return nil, err
 	}
 
-	if authMethodsCallback == nil {
-		return nil, errors.New("empty parameter authMethodsCallback")
-	}
+	return NewFromCore(c)
+}
 
-	if passwordVerifyCallback == nil {
-		return nil, errors.New("empty parameter passwordVerifyCallback")
+// NewFromCore returns Gin handler which can be used in Gin Web Framework, requests are handled by given core.
+func NewFromCore(core *core.Core) (*GinHandler, error) {
+	if core == nil {
+		return nil, errors.New("empty parameter core")
 	}
 
 	return &GinHandler{
-		logger:                 logger,
-		usernameHash:           usernameHash,
-		passwordHash:           passwordHash,
-		authMethodsCallback:    authMethodsCallback,
-		passwordVerifyCallback: passwordVerifyCallback,
+		core: core,
 	}, nil
 }
 
 // Handle handles the Corbado webhook request.
 func (g *GinHandler) Handle(c *gin.Context) {
-	g.logger.Debug("%s %s", c.Request.Method, c.Request.URL.String())
-
-	username, password, ok := c.Request.BasicAuth()
-	if !ok {
-		c.Header("WWW-Authenticate", `Basic realm="restricted", charset="UTF-8"`)
-		c.String(http.StatusUnauthorized, "Unauthorized")
-
-		return
-	}
-
-	usernameHash := sha256.Sum256([]byte(username))
-	passwordHash := sha256.Sum256([]byte(password))
-
-	usernameMatch := subtle.ConstantTimeCompare(g.usernameHash[:], usernameHash[:]) == 1
-	passwordMatch := subtle.ConstantTimeCompare(g.passwordHash[:], passwordHash[:]) == 1
-
-	if !usernameMatch || !passwordMatch {
-		c.Header("WWW-Authenticate", `Basic realm="restricted", charset="UTF-8"`)
-		c.String(http.StatusUnauthorized, "Unauthorized")
-
-		return
-	}
-
-	action := c.GetHeader("X-Corbado-Action")
-	if action == "" {
-		c.String(http.StatusBadRequest, "X-Corbado-Action header missing or empty")
-
-		return
-	}
-
-	body, err := c.GetRawData()
-	if err != nil {
-		g.sendInternalServerError(c, err)
-
-		return
-	}
-
-	if len(body) == 0 {
-		c.String(http.StatusBadRequest, "Empty body, provide JSON request")
-
-		return
-	}
-
-	switch action {
-	case "authMethods":
-		g.handleAuthMethods(c, body)
-
-	case "passwordVerify":
-		g.handlePasswordVerify(c, body)
-
-	default:
-		c.String(http.StatusBadRequest, fmt.Sprintf("Invalid action given in X-Corbado-Action header ('%s')", action))
-	}
-}
-
-func (g *GinHandler) handleAuthMethods(c *gin.Context, body []byte) {
-	req, err := authmethodsrequest.NewFromBody(body)
-	if err != nil {
-		g.sendInternalServerError(c, err)
-
-		return
-	}
+	resp := g.core.Handle(&core.Request{
+		Context: c.Request.Context(),
+		Method:  c.Request.Method,
+		URL:     c.Request.URL.String(),
+		Header:  c.Request.Header,
+		Body:    c.Request.Body,
 
-	if req.Data.Username == "" {
-		c.String(http.StatusBadRequest, "username must not be empty")
+		RemoteAddr: c.Request.RemoteAddr,
+	})
 
-		return
+	if err := resp.Send(c.Writer); err != nil {
+		g.core.Logger().Log(logger.LevelError, "Sending response failed", logger.Err(err))
 	}
-
-	status, err := g.authMethodsCallback(req.Data.Username)
-	if err != nil {
-		g.sendInternalServerError(c, err)
-
-		return
-	}
-
-	resp, err := authmethodsresponse.New("", status)
-	if err != nil {
-		g.sendInternalServerError(c, err)
-
-		return
-	}
-
-	c.JSON(http.StatusOK, resp)
-}
-
-func (g *GinHandler) handlePasswordVerify(c *gin.Context, body []byte) {
-	req, err := passwordverifyrequest.NewFromBody(body)
-	if err != nil {
-		g.sendInternalServerError(c, err)
-
-		return
-	}
-
-	if req.Data.Username == "" {
-		c.String(http.StatusBadRequest, "username must not be empty")
-
-		return
-	}
-
-	if req.Data.Password == "" {
-		c.String(http.StatusBadRequest, "password must not be empty")
-
-		return
-	}
-
-	success, err := g.passwordVerifyCallback(req.Data.Username, req.Data.Password)
-	if err != nil {
-		g.sendInternalServerError(c, err)
-
-		return
-	}
-
-	resp, err := passwordverifyresponse.New("", success)
-	if err != nil {
-		g.sendInternalServerError(c, err)
-
-		return
-	}
-
-	c.JSON(http.StatusOK, resp)
-}
-
-func (g *GinHandler) sendInternalServerError(c *gin.Context, err error) {
-	g.logger.Error(err)
-	c.Status(http.StatusInternalServerError)
 }
@@ -0,0 +1,47 @@
+package hooks
+
+import (
+	"context"
+	"net/netip"
+	"time"
+)
+
+// Hooks allows observing the handling of webhook requests (for metrics or alerting for example). All
+// functions are optional and must be safe for concurrent use.
+type Hooks struct {
+	// OnPanic is called when a panic was recovered while handling a request, err contains the panic
+	// value and the stack trace.
+	OnPanic func(ctx context.Context, action string, err error)
+
+	// OnAuthFailure is called when a request failed authentication (Basic Auth or other authenticator and
+	// signature verification), clientIP is the address of the client.
+	OnAuthFailure func(ctx context.Context, clientIP netip.Addr)
+
+	// OnLockout is called when a client address got locked for given duration after repeated
+	// authentication failures.
+	OnLockout func(ctx context.Context, clientIP netip.Addr, duration time.Duration)
+
+	// OnHandled is called when the handler (callback) of an action returned, err is the error it returned.
+	// It is also called for handlers which were abandoned after their timeout.
+	OnHandled func(ctx context.Context, action string, duration time.Duration, err error)
+
+	// OnRequest is called when a request was handled (before the response is sent).
+	OnRequest func(ctx context.Context, request *Request)
+}
+
+// Request describes a handled webhook request.
+type Request struct {
+	// Action is the name of the action, empty if the action is missing or unknown.
+	Action     string
+	ProjectID  string
+	RequestID  string
+	ResponseID string
+	StatusCode int
+
+	// Outcome is the outcome of the action (for example "exists" or "not_exists" for action 'authMethods',
+	// "success" or "failure" for action 'passwordVerify'), "error" for error responses.
+	Outcome string
+
+	// Duration is the duration from the start of the request until the response was ready.
+	Duration time.Duration
+}
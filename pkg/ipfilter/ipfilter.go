@@ -0,0 +1,220 @@
+package ipfilter
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// ForwardedHeader defines which header the client address is taken from if the request comes from a trusted
+// proxy. Only the configured header is used (there is no fallback to the other one), as a proxy passes the
+// other header through unchanged, so the client could set it to any address.
+type ForwardedHeader int
+
+const (
+	// ForwardedHeaderXForwardedFor uses the X-Forwarded-For header, this is the default.
+	ForwardedHeaderXForwardedFor ForwardedHeader = iota
+
+	// ForwardedHeaderForwarded uses the Forwarded header (RFC 7239).
+	ForwardedHeaderForwarded
+)
+
+// Filter allows requests only from configured address ranges (CIDRs). The client address is taken from the
+// configured forwarding header only if the request comes from a trusted proxy. Both lists can be updated at
+// runtime with Update().
+type Filter struct {
+	header ForwardedHeader
+	lists  atomic.Pointer[lists]
+}
+
+type lists struct {
+	allowed        []netip.Prefix
+	trustedProxies []netip.Prefix
+}
+
+// New returns new filter which allows given CIDRs (for example "192.0.2.0/24" or "2001:db8::/32", single
+// addresses are allowed too) and trusts given forwarding header from given proxy CIDRs (can be empty). Use
+// the header your proxies set, the other header is ignored.
+func New(allowed []string, trustedProxies []string, header ForwardedHeader) (*Filter, error) {
+	if header != ForwardedHeaderXForwardedFor && header != ForwardedHeaderForwarded {
+		return nil, errors.Errorf("invalid parameter header (%d)", header)
+	}
+
+	f := &Filter{header: header}
+	if err := f.Update(allowed, trustedProxies); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Update atomically replaces the allowed and trusted proxy CIDRs, requests which are already being
+// handled are not affected.
+func (f *Filter) Update(allowed []string, trustedProxies []string) error {
+	if len(allowed) == 0 {
+		return errors.New("empty parameter allowed")
+	}
+
+	allowedPrefixes, err := parsePrefixes(allowed)
+	if err != nil {
+		return errors.WithMessage(err, "invalid parameter allowed")
+	}
+
+	trustedProxyPrefixes, err := parsePrefixes(trustedProxies)
+	if err != nil {
+		return errors.WithMessage(err, "invalid parameter trustedProxies")
+	}
+
+	f.lists.Store(&lists{
+		allowed:        allowedPrefixes,
+		trustedProxies: trustedProxyPrefixes,
+	})
+
+	return nil
+}
+
+// ClientIP returns the address of the client for given remote address (address of the peer, for example
+// http.Request.RemoteAddr) and headers. The result is invalid if no address could be determined.
+func (f *Filter) ClientIP(remoteAddr string, header http.Header) netip.Addr {
+	return clientIP(f.lists.Load().trustedProxies, f.header, remoteAddr, header)
+}
+
+// Allowed returns true if given client address is in one of the allowed CIDRs.
+func (f *Filter) Allowed(clientIP netip.Addr) bool {
+	if !clientIP.IsValid() {
+		return false
+	}
+
+	return contains(f.lists.Load().allowed, clientIP)
+}
+
+// ClientIPFromRemoteAddr returns the address of given remote address (without trusting any headers), the
+// result is invalid if the remote address cannot be parsed.
+func ClientIPFromRemoteAddr(remoteAddr string) netip.Addr {
+	return parseAddr(remoteAddr)
+}
+
+// clientIP returns the address of the client for given remote address and headers, the configured forwarding
+// header is only used if the remote address is one of given trusted proxies.
+func clientIP(trustedProxies []netip.Prefix, forwardedHeader ForwardedHeader, remoteAddr string, header http.Header) netip.Addr {
+	peer := parseAddr(remoteAddr)
+	if !peer.IsValid() || !contains(trustedProxies, peer) {
+		return peer
+	}
+
+	var hops []netip.Addr
+	if forwardedHeader == ForwardedHeaderForwarded {
+		hops = forwarded(header)
+	} else {
+		hops = xForwardedFor(header)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !hops[i].IsValid() || !contains(trustedProxies, hops[i]) {
+			return hops[i]
+		}
+	}
+
+	if len(hops) > 0 {
+		return hops[0]
+	}
+
+	return peer
+}
+
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+func contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseAddr parses given address with optional port (IPv6 addresses with port in brackets), the result is
+// invalid if it cannot be parsed.
+func parseAddr(value string) netip.Addr {
+	value = strings.TrimSpace(value)
+
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}
+	}
+
+	return addr.Unmap()
+}
+
+// forwarded returns the addresses of all hops from Forwarded headers (RFC 7239) in order (client first). Hops
+// which cannot be parsed (for example obfuscated ones) are invalid.
+func forwarded(header http.Header) []netip.Addr {
+	hops := make([]netip.Addr, 0)
+
+	for _, value := range header.Values("Forwarded") {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if found && strings.EqualFold(key, "for") {
+					hops = append(hops, parseAddr(strings.Trim(val, `"`)))
+				}
+			}
+		}
+	}
+
+	return hops
+}
+
+// xForwardedFor returns the addresses of all hops from X-Forwarded-For headers in order (client first). Hops
+// which cannot be parsed are invalid.
+func xForwardedFor(header http.Header) []netip.Addr {
+	hops := make([]netip.Addr, 0)
+
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, parseAddr(hop))
+		}
+	}
+
+	return hops
+}
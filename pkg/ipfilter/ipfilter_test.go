@@ -0,0 +1,148 @@
+package ipfilter_test
+
+import (
+	"net/http"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/ipfilter"
+)
+
+func TestNew(t *testing.T) {
+	f, err := ipfilter.New(nil, nil, ipfilter.ForwardedHeaderXForwardedFor)
+	assert.ErrorContains(t, err, "empty parameter allowed")
+	assert.Nil(t, f)
+
+	f, err = ipfilter.New([]string{"192.0.2.0/33"}, nil, ipfilter.ForwardedHeaderXForwardedFor)
+	assert.ErrorContains(t, err, "invalid parameter allowed")
+	assert.Nil(t, f)
+
+	f, err = ipfilter.New([]string{"192.0.2.0/24"}, []string{"invalid"}, ipfilter.ForwardedHeaderXForwardedFor)
+	assert.ErrorContains(t, err, "invalid parameter trustedProxies")
+	assert.Nil(t, f)
+
+	f, err = ipfilter.New([]string{"192.0.2.0/24"}, nil, 42)
+	assert.ErrorContains(t, err, "invalid parameter header (42)")
+	assert.Nil(t, f)
+}
+
+func TestClientIP(t *testing.T) {
+	trustedProxies := []string{"10.0.0.0/8", "2001:db8::1"}
+
+	tests := []struct {
+		name       string
+		header     ipfilter.ForwardedHeader
+		remoteAddr string
+		headers    http.Header
+		expected   string
+	}{
+		{
+			name:       "Untrusted peer, headers ignored",
+			header:     ipfilter.ForwardedHeaderXForwardedFor,
+			remoteAddr: "198.51.100.1:1234",
+			headers:    http.Header{"X-Forwarded-For": {"192.0.2.1"}},
+			expected:   "198.51.100.1",
+		},
+		{
+			name:       "Trusted peer without headers",
+			header:     ipfilter.ForwardedHeaderXForwardedFor,
+			remoteAddr: "10.0.0.1:1234",
+			headers:    http.Header{},
+			expected:   "10.0.0.1",
+		},
+		{
+			name:       "Trusted peer with X-Forwarded-For",
+			header:     ipfilter.ForwardedHeaderXForwardedFor,
+			remoteAddr: "10.0.0.1:1234",
+			headers:    http.Header{"X-Forwarded-For": {"203.0.113.1, 192.0.2.1, 10.0.0.2"}},
+			expected:   "192.0.2.1",
+		},
+		{
+			name:       "Spoofed Forwarded behind X-Forwarded-For proxy",
+			header:     ipfilter.ForwardedHeaderXForwardedFor,
+			remoteAddr: "10.0.0.1:1234",
+			headers:    http.Header{"Forwarded": {"for=192.0.2.1"}, "X-Forwarded-For": {"198.51.100.9"}},
+			expected:   "198.51.100.9",
+		},
+		{
+			name:       "Spoofed Forwarded without X-Forwarded-For",
+			header:     ipfilter.ForwardedHeaderXForwardedFor,
+			remoteAddr: "10.0.0.1:1234",
+			headers:    http.Header{"Forwarded": {"for=192.0.2.1"}},
+			expected:   "10.0.0.1",
+		},
+		{
+			name:       "Trusted IPv6 peer with Forwarded",
+			header:     ipfilter.ForwardedHeaderForwarded,
+			remoteAddr: "[2001:db8::1]:1234",
+			headers:    http.Header{"Forwarded": {`for="[2001:db8::2]:4711";proto=https, for=10.0.0.3`}},
+			expected:   "2001:db8::2",
+		},
+		{
+			name:       "Spoofed X-Forwarded-For behind Forwarded proxy",
+			header:     ipfilter.ForwardedHeaderForwarded,
+			remoteAddr: "10.0.0.1:1234",
+			headers:    http.Header{"Forwarded": {"for=198.51.100.9"}, "X-Forwarded-For": {"192.0.2.1"}},
+			expected:   "198.51.100.9",
+		},
+		{
+			name:       "Obfuscated hop",
+			header:     ipfilter.ForwardedHeaderForwarded,
+			remoteAddr: "10.0.0.1:1234",
+			headers:    http.Header{"Forwarded": {"for=192.0.2.5, for=_hidden"}},
+			expected:   "invalid IP",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := ipfilter.New([]string{"192.0.2.0/24"}, trustedProxies, test.header)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, f.ClientIP(test.remoteAddr, test.headers).String())
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	f, err := ipfilter.New([]string{"192.0.2.0/24", "2001:db8::/32"}, nil, ipfilter.ForwardedHeaderXForwardedFor)
+	require.NoError(t, err)
+
+	assert.True(t, f.Allowed(netip.MustParseAddr("192.0.2.10")))
+	assert.True(t, f.Allowed(ipfilter.ClientIPFromRemoteAddr("[::ffff:192.0.2.10]:1234")))
+	assert.True(t, f.Allowed(netip.MustParseAddr("2001:db8::10")))
+	assert.False(t, f.Allowed(netip.MustParseAddr("198.51.100.1")))
+	assert.False(t, f.Allowed(netip.Addr{}))
+
+	require.NoError(t, f.Update([]string{"198.51.100.1"}, nil))
+	assert.False(t, f.Allowed(netip.MustParseAddr("192.0.2.10")))
+	assert.True(t, f.Allowed(netip.MustParseAddr("198.51.100.1")))
+}
+
+func TestResolver(t *testing.T) {
+	r, err := ipfilter.NewResolver(nil, ipfilter.ForwardedHeaderXForwardedFor)
+	assert.ErrorContains(t, err, "empty parameter trustedProxies")
+	assert.Nil(t, r)
+
+	r, err = ipfilter.NewResolver([]string{"invalid"}, ipfilter.ForwardedHeaderXForwardedFor)
+	assert.ErrorContains(t, err, "invalid parameter trustedProxies")
+	assert.Nil(t, r)
+
+	r, err = ipfilter.NewResolver([]string{"10.0.0.0/8"}, 42)
+	assert.ErrorContains(t, err, "invalid parameter header (42)")
+	assert.Nil(t, r)
+
+	r, err = ipfilter.NewResolver([]string{"10.0.0.0/8"}, ipfilter.ForwardedHeaderForwarded)
+	require.NoError(t, err)
+
+	headers := http.Header{"Forwarded": {"for=198.51.100.9"}, "X-Forwarded-For": {"192.0.2.1"}}
+	assert.Equal(t, "198.51.100.9", r.ClientIP("10.0.0.1:1234", headers).String())
+	assert.Equal(t, "203.0.113.1", r.ClientIP("203.0.113.1:1234", headers).String())
+
+	require.NoError(t, r.Update([]string{"203.0.113.1"}))
+	assert.Equal(t, "10.0.0.1", r.ClientIP("10.0.0.1:1234", headers).String())
+	assert.Equal(t, "198.51.100.9", r.ClientIP("203.0.113.1:1234", headers).String())
+}
@@ -0,0 +1,56 @@
+package ipfilter
+
+import (
+	"net/http"
+	"net/netip"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Resolver determines the client address like Filter but does not restrict client addresses. Use it if
+// requests come through proxies but no filter is needed (the lockout is keyed by the client address, without
+// resolver all requests through a proxy share the address of the proxy). The trusted proxies can be updated
+// at runtime with Update().
+type Resolver struct {
+	header         ForwardedHeader
+	trustedProxies atomic.Pointer[[]netip.Prefix]
+}
+
+// NewResolver returns new resolver which trusts given forwarding header from given proxy CIDRs. Use the
+// header your proxies set, the other header is ignored.
+func NewResolver(trustedProxies []string, header ForwardedHeader) (*Resolver, error) {
+	if header != ForwardedHeaderXForwardedFor && header != ForwardedHeaderForwarded {
+		return nil, errors.Errorf("invalid parameter header (%d)", header)
+	}
+
+	r := &Resolver{header: header}
+	if err := r.Update(trustedProxies); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Update atomically replaces the trusted proxy CIDRs, requests which are already being handled are not
+// affected.
+func (r *Resolver) Update(trustedProxies []string) error {
+	if len(trustedProxies) == 0 {
+		return errors.New("empty parameter trustedProxies")
+	}
+
+	prefixes, err := parsePrefixes(trustedProxies)
+	if err != nil {
+		return errors.WithMessage(err, "invalid parameter trustedProxies")
+	}
+
+	r.trustedProxies.Store(&prefixes)
+
+	return nil
+}
+
+// ClientIP returns the address of the client for given remote address (address of the peer, for example
+// http.Request.RemoteAddr) and headers. The result is invalid if no address could be determined.
+func (r *Resolver) ClientIP(remoteAddr string, header http.Header) netip.Addr {
+	return clientIP(*r.trustedProxies.Load(), r.header, remoteAddr, header)
+}
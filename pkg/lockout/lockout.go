@@ -0,0 +1,178 @@
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultThreshold is the number of failures after which a key is locked if none is configured.
+	DefaultThreshold = 5
+
+	// DefaultBaseDuration is the duration of the first lock if none is configured.
+	DefaultBaseDuration = time.Minute
+
+	// DefaultMaxDuration is the maximum duration of a lock if none is configured.
+	DefaultMaxDuration = time.Hour
+
+	// DefaultWindow is the duration after the last failure after which failures are forgotten if none is
+	// configured.
+	DefaultWindow = 24 * time.Hour
+)
+
+// State is the stored failure state of a key (a client address for example).
+type State struct {
+	// Failures is the number of failures within the window.
+	Failures int
+
+	// LockedUntil is the time until the key is locked (zero if never locked).
+	LockedUntil time.Time
+}
+
+// RetryAfter returns the remaining lock duration at given time (zero if not locked).
+func (s *State) RetryAfter(now time.Time) time.Duration {
+	if s == nil || !now.Before(s.LockedUntil) {
+		return 0
+	}
+
+	return s.LockedUntil.Sub(now)
+}
+
+// Store stores the failure states of keys. Implementations must be safe for concurrent use, implementations
+// backed by a shared database (Redis for example) make the lockout work across replicas.
+type Store interface {
+	// Get returns the state of given key, nil if nothing is stored.
+	Get(ctx context.Context, key string, now time.Time) (*State, error)
+
+	// AddFailure atomically increments the failures of given key and returns the updated state. Failures are
+	// forgotten if no further failure happens within window.
+	AddFailure(ctx context.Context, key string, now time.Time, window time.Duration) (*State, error)
+
+	// Lock locks given key until given time (failures must be kept).
+	Lock(ctx context.Context, key string, until time.Time) error
+
+	// Reset removes the state of given key.
+	Reset(ctx context.Context, key string) error
+}
+
+// Config configures the lockout, zero values are replaced by their defaults.
+type Config struct {
+	// Threshold is the number of failures after which a key is locked.
+	Threshold int
+
+	// BaseDuration is the duration of the first lock, every further failure doubles it (exponential
+	// back-off).
+	BaseDuration time.Duration
+
+	// MaxDuration is the maximum duration of a lock.
+	MaxDuration time.Duration
+
+	// Window is the duration after the last failure after which failures are forgotten.
+	Window time.Duration
+}
+
+// Lockout temporarily locks keys (client addresses for example) after repeated failures.
+type Lockout struct {
+	store        Store
+	threshold    int
+	baseDuration time.Duration
+	maxDuration  time.Duration
+	window       time.Duration
+}
+
+// New returns new lockout with given store and config (nil config for defaults).
+func New(store Store, config *Config) (*Lockout, error) {
+	if store == nil {
+		return nil, errors.New("empty parameter store")
+	}
+
+	if config == nil {
+		config = &Config{}
+	}
+
+	if config.Threshold < 0 {
+		return nil, errors.Errorf("invalid parameter config.Threshold (%d)", config.Threshold)
+	}
+
+	if config.BaseDuration < 0 || config.MaxDuration < 0 || config.Window < 0 {
+		return nil, errors.New("invalid parameter config, durations must not be negative")
+	}
+
+	l := &Lockout{
+		store:        store,
+		threshold:    config.Threshold,
+		baseDuration: config.BaseDuration,
+		maxDuration:  config.MaxDuration,
+		window:       config.Window,
+	}
+
+	if l.threshold == 0 {
+		l.threshold = DefaultThreshold
+	}
+
+	if l.baseDuration == 0 {
+		l.baseDuration = DefaultBaseDuration
+	}
+
+	if l.maxDuration == 0 {
+		l.maxDuration = DefaultMaxDuration
+	}
+
+	if l.window == 0 {
+		l.window = DefaultWindow
+	}
+
+	if l.baseDuration > l.maxDuration {
+		return nil, errors.Errorf("invalid parameter config, base duration (%s) is greater than max duration (%s)", l.baseDuration, l.maxDuration)
+	}
+
+	return l, nil
+}
+
+// Check returns the state of given key (nil if there were no failures), use State.RetryAfter() to check if
+// the key is locked.
+func (l *Lockout) Check(ctx context.Context, key string, now time.Time) (*State, error) {
+	return l.store.Get(ctx, key, now)
+}
+
+// Failure records a failure for given key and returns the lock duration if the key got locked (zero
+// otherwise).
+func (l *Lockout) Failure(ctx context.Context, key string, now time.Time) (time.Duration, error) {
+	state, err := l.store.AddFailure(ctx, key, now, l.window)
+	if err != nil {
+		return 0, err
+	}
+
+	if state.Failures < l.threshold {
+		return 0, nil
+	}
+
+	duration := l.lockDuration(state.Failures)
+	if err := l.store.Lock(ctx, key, now.Add(duration)); err != nil {
+		return 0, err
+	}
+
+	return duration, nil
+}
+
+// Reset forgets all failures of given key (after a successful attempt or to unlock a key manually).
+func (l *Lockout) Reset(ctx context.Context, key string) error {
+	return l.store.Reset(ctx, key)
+}
+
+// lockDuration returns the base duration doubled for every failure above the threshold (capped at the max
+// duration).
+func (l *Lockout) lockDuration(failures int) time.Duration {
+	duration := l.baseDuration
+	for i := l.threshold; i < failures && duration < l.maxDuration; i++ {
+		duration *= 2
+	}
+
+	if duration > l.maxDuration {
+		return l.maxDuration
+	}
+
+	return duration
+}
@@ -0,0 +1,133 @@
+package lockout_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/lockout"
+)
+
+func TestNew(t *testing.T) {
+	store, err := lockout.NewMemoryStore(0)
+	require.NoError(t, err)
+
+	l, err := lockout.New(nil, nil)
+	assert.ErrorContains(t, err, "empty parameter store")
+	assert.Nil(t, l)
+
+	l, err = lockout.New(store, &lockout.Config{Threshold: -1})
+	assert.ErrorContains(t, err, "invalid parameter config.Threshold")
+	assert.Nil(t, l)
+
+	l, err = lockout.New(store, &lockout.Config{BaseDuration: 2 * time.Hour})
+	assert.ErrorContains(t, err, "base duration (2h0m0s) is greater than max duration (1h0m0s)")
+	assert.Nil(t, l)
+
+	l, err = lockout.New(store, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, l)
+}
+
+func TestLockout(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store, err := lockout.NewMemoryStore(0)
+	require.NoError(t, err)
+
+	l, err := lockout.New(store, &lockout.Config{
+		Threshold:    3,
+		BaseDuration: time.Minute,
+		MaxDuration:  5 * time.Minute,
+		Window:       time.Hour,
+	})
+	require.NoError(t, err)
+
+	state, err := l.Check(ctx, "10.0.0.1", now)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+	assert.Zero(t, state.RetryAfter(now))
+
+	for i := 0; i < 2; i++ {
+		duration, err := l.Failure(ctx, "10.0.0.1", now)
+		require.NoError(t, err)
+		assert.Zero(t, duration)
+	}
+
+	state, err = l.Check(ctx, "10.0.0.1", now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, state.Failures)
+	assert.Zero(t, state.RetryAfter(now))
+
+	// exponential back-off capped at max duration
+	for _, expected := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute} {
+		duration, err := l.Failure(ctx, "10.0.0.1", now)
+		require.NoError(t, err)
+		assert.Equal(t, expected, duration)
+	}
+
+	state, err = l.Check(ctx, "10.0.0.1", now)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, state.RetryAfter(now))
+	assert.Zero(t, state.RetryAfter(now.Add(5*time.Minute)))
+
+	// other keys are not affected
+	state, err = l.Check(ctx, "10.0.0.2", now)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	// failures are forgotten after the window
+	state, err = l.Check(ctx, "10.0.0.1", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	// reset
+	_, err = l.Failure(ctx, "10.0.0.2", now)
+	require.NoError(t, err)
+	require.NoError(t, l.Reset(ctx, "10.0.0.2"))
+
+	state, err = l.Check(ctx, "10.0.0.2", now)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store, err := lockout.NewMemoryStore(-1)
+	assert.ErrorContains(t, err, "invalid parameter capacity (-1)")
+	assert.Nil(t, store)
+
+	store, err = lockout.NewMemoryStore(2)
+	require.NoError(t, err)
+
+	_, err = store.AddFailure(ctx, "10.0.0.1", now, time.Hour)
+	require.NoError(t, err)
+
+	_, err = store.AddFailure(ctx, "10.0.0.2", now, time.Hour)
+	require.NoError(t, err)
+
+	// the least recently used key is evicted ("10.0.0.1" was used last, so "10.0.0.2" is evicted)
+	state, err := store.Get(ctx, "10.0.0.1", now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.Failures)
+
+	require.NoError(t, store.Lock(ctx, "10.0.0.3", now.Add(time.Minute)))
+
+	state, err = store.Get(ctx, "10.0.0.2", now)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	state, err = store.Get(ctx, "10.0.0.1", now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.Failures)
+
+	state, err = store.Get(ctx, "10.0.0.3", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), state.LockedUntil)
+}
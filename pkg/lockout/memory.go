@@ -0,0 +1,150 @@
+package lockout
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultCapacity is the number of keys the in-memory store remembers if none is configured.
+const DefaultCapacity = 10000
+
+// MemoryStore is an in-memory store, it only works for a single instance (use a shared store for replicas).
+type MemoryStore struct {
+	capacity int
+	mutex    sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+var _ Store = &MemoryStore{}
+
+type memoryEntry struct {
+	key       string
+	state     State
+	expiresAt time.Time
+}
+
+// NewMemoryStore returns new in-memory store which remembers up to capacity keys (least recently used ones
+// are evicted first). Use zero capacity for DefaultCapacity.
+func NewMemoryStore(capacity int) (*MemoryStore, error) {
+	if capacity < 0 {
+		return nil, errors.Errorf("invalid parameter capacity (%d)", capacity)
+	}
+
+	if capacity == 0 {
+		capacity = DefaultCapacity
+	}
+
+	return &MemoryStore{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}, nil
+}
+
+// Get returns the state of given key, nil if nothing is stored.
+func (m *MemoryStore) Get(_ context.Context, key string, now time.Time) (*State, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry := m.get(key, now)
+	if entry == nil {
+		return nil, nil
+	}
+
+	state := entry.state
+
+	return &state, nil
+}
+
+// AddFailure increments the failures of given key and returns the updated state.
+func (m *MemoryStore) AddFailure(_ context.Context, key string, now time.Time, window time.Duration) (*State, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry := m.get(key, now)
+	if entry == nil {
+		entry = m.add(key)
+	}
+
+	entry.state.Failures++
+	if expiresAt := now.Add(window); expiresAt.After(entry.expiresAt) {
+		entry.expiresAt = expiresAt
+	}
+
+	state := entry.state
+
+	return &state, nil
+}
+
+// Lock locks given key until given time.
+func (m *MemoryStore) Lock(_ context.Context, key string, until time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var entry *memoryEntry
+	if element, exists := m.entries[key]; exists {
+		m.order.MoveToFront(element)
+		entry = element.Value.(*memoryEntry)
+	} else {
+		entry = m.add(key)
+	}
+
+	entry.state.LockedUntil = until
+	if until.After(entry.expiresAt) {
+		entry.expiresAt = until
+	}
+
+	return nil
+}
+
+// Reset removes the state of given key.
+func (m *MemoryStore) Reset(_ context.Context, key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if element, exists := m.entries[key]; exists {
+		m.remove(element)
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) get(key string, now time.Time) *memoryEntry {
+	element, exists := m.entries[key]
+	if !exists {
+		return nil
+	}
+
+	entry := element.Value.(*memoryEntry)
+	if !now.Before(entry.expiresAt) {
+		m.remove(element)
+
+		return nil
+	}
+
+	m.order.MoveToFront(element)
+
+	return entry
+}
+
+// add adds an empty entry for given key, evicting the least recently used entries if the store is full.
+func (m *MemoryStore) add(key string) *memoryEntry {
+	for m.order.Len() >= m.capacity {
+		m.remove(m.order.Back())
+	}
+
+	entry := &memoryEntry{key: key}
+	m.entries[key] = m.order.PushFront(entry)
+
+	return entry
+}
+
+func (m *MemoryStore) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryEntry).key)
+}
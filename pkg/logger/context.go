@@ -0,0 +1,21 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of given context which carries given logger.
+func NewContext(ctx context.Context, logger Leveled) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by given context, the null logger if there is none. The webhook
+// passes a logger enriched with the fields of the request (action, requestID, projectID, responseID and
+// remoteAddr) to callbacks, so their messages can be correlated with the messages of the webhook.
+func FromContext(ctx context.Context) Leveled {
+	if logger, ok := ctx.Value(contextKey{}).(Leveled); ok {
+		return logger
+	}
+
+	return NewNull()
+}
@@ -0,0 +1,17 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/corbado/webhook-go/pkg/logger"
+)
+
+func TestFromContext(t *testing.T) {
+	assert.IsType(t, &logger.Null{}, logger.FromContext(context.Background()))
+
+	leveled := logger.NewLeveled(&recorder{})
+	assert.Same(t, leveled, logger.FromContext(logger.NewContext(context.Background(), leveled)))
+}
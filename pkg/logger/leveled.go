@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Level is the severity of a log message.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the name of the level.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+// Field is a key/value pair attached to a log message.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// F returns new field with given key and value.
+func F(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err returns new field with key "error" and given error (implementations preserve its stack trace).
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// Leveled is a leveled logger with key/value fields, the handlers log through it. Loggers which only
+// implement Logger are adapted with NewLeveled().
+type Leveled interface {
+	Log(level Level, msg string, fields ...Field)
+
+	// With returns a logger which adds given fields to all messages.
+	With(fields ...Field) Leveled
+}
+
+// infoLogger and warnLogger are implemented by loggers which support more levels than Logger (like Impl).
+type infoLogger interface {
+	Info(msg string, args ...any)
+}
+
+type warnLogger interface {
+	Warn(msg string, args ...any)
+}
+
+type adapter struct {
+	logger Logger
+	fields []Field
+}
+
+var _ Leveled = &adapter{}
+
+// NewLeveled returns given logger as leveled logger. Loggers which implement Leveled are returned as is,
+// for other loggers fields are appended to the message as key=value pairs. Info and Warn messages are
+// passed to Info() and Warn() if the logger implements them (Debug() otherwise), Error messages are passed
+// to Error() (with the error of the "error" field wrapped, so its stack trace is kept).
+func NewLeveled(logger Logger) Leveled {
+	if leveled, ok := logger.(Leveled); ok {
+		return leveled
+	}
+
+	return &adapter{logger: logger}
+}
+
+// Log logs given message with given fields.
+func (a *adapter) Log(level Level, msg string, fields ...Field) {
+	all := make([]Field, 0, len(a.fields)+len(fields))
+	all = append(all, a.fields...)
+	all = append(all, fields...)
+
+	switch level {
+	case LevelError:
+		a.logger.Error(fieldsError(msg, all))
+	case LevelWarn:
+		if warn, ok := a.logger.(warnLogger); ok {
+			warn.Warn("%s", formatMessage(msg, all))
+		} else {
+			a.logger.Debug("[WARN] %s", formatMessage(msg, all))
+		}
+	case LevelInfo:
+		if info, ok := a.logger.(infoLogger); ok {
+			info.Info("%s", formatMessage(msg, all))
+		} else {
+			a.logger.Debug("[INFO] %s", formatMessage(msg, all))
+		}
+	default:
+		a.logger.Debug("%s", formatMessage(msg, all))
+	}
+}
+
+// With returns an adapter which adds given fields to all messages.
+func (a *adapter) With(fields ...Field) Leveled {
+	all := make([]Field, 0, len(a.fields)+len(fields))
+	all = append(all, a.fields...)
+	all = append(all, fields...)
+
+	return &adapter{logger: a.logger, fields: all}
+}
+
+// fieldsError returns the error of the first error field wrapped with the message and the other fields (or a
+// new error if there is no error field).
+func fieldsError(msg string, fields []Field) error {
+	others := make([]Field, 0, len(fields))
+
+	var err error
+	for _, field := range fields {
+		if fieldErr, ok := field.Value.(error); ok && err == nil {
+			err = fieldErr
+
+			continue
+		}
+
+		others = append(others, field)
+	}
+
+	if err == nil {
+		return errors.New(formatMessage(msg, others))
+	}
+
+	return errors.WithMessage(err, formatMessage(msg, others))
+}
+
+// formatMessage appends given fields to given message as key=value pairs (values with spaces are quoted).
+func formatMessage(msg string, fields []Field) string {
+	if len(fields) == 0 {
+		return msg
+	}
+
+	var b strings.Builder
+	b.WriteString(msg)
+
+	for _, field := range fields {
+		value := fmt.Sprintf("%v", field.Value)
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+
+		b.WriteString(" ")
+		b.WriteString(field.Key)
+		b.WriteString("=")
+		b.WriteString(value)
+	}
+
+	return b.String()
+}
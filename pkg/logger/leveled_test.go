@@ -0,0 +1,56 @@
+package logger_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/corbado/webhook-go/pkg/logger"
+)
+
+type recorder struct {
+	lines []string
+}
+
+func (r *recorder) Debug(msg string, args ...any) {
+	r.lines = append(r.lines, "DEBUG "+fmt.Sprintf(msg, args...))
+}
+
+func (r *recorder) Error(err error) {
+	r.lines = append(r.lines, "ERROR "+err.Error())
+}
+
+func TestNewLeveled(t *testing.T) {
+	null := logger.NewNull()
+	assert.Same(t, null, logger.NewLeveled(null))
+
+	r := &recorder{}
+	leveled := logger.NewLeveled(r).With(logger.F("action", "authMethods"))
+
+	leveled.Log(logger.LevelDebug, "Handling webhook request", logger.F("url", "/webhook"))
+	leveled.Log(logger.LevelInfo, "Webhook request handled", logger.F("status", 200), logger.F("reason", "two words"))
+	leveled.Log(logger.LevelWarn, "Authentication failed", logger.F("clientIP", ""))
+	leveled.Log(logger.LevelError, "Internal server error", logger.Err(errors.New("connection refused")), logger.F("status", 500))
+
+	assert.Equal(t, []string{
+		"DEBUG Handling webhook request action=authMethods url=/webhook",
+		`DEBUG [INFO] Webhook request handled action=authMethods status=200 reason="two words"`,
+		`DEBUG [WARN] Authentication failed action=authMethods clientIP=""`,
+		"ERROR Internal server error action=authMethods status=500: connection refused",
+	}, r.lines)
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, "DEBUG", logger.LevelDebug.String())
+	assert.Equal(t, "ERROR", logger.LevelError.String())
+	assert.Equal(t, "LEVEL(7)", logger.Level(7).String())
+}
+
+func TestStackTrace(t *testing.T) {
+	assert.Empty(t, logger.StackTrace(fmt.Errorf("plain")))
+
+	err := fmt.Errorf("wrapped: %w", errors.New("with stack"))
+	assert.Contains(t, logger.StackTrace(err), "logger_test.TestStackTrace")
+}
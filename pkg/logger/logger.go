@@ -5,6 +5,8 @@ import (
 	"log"
 )
 
+// Logger is the logger interface of the webhook, implement Leveled in addition for leveled logging with
+// key/value fields (see NewLeveled()).
 type Logger interface {
 	Debug(msg string, args ...any)
 	Error(err error)
@@ -25,6 +27,16 @@ func (i *Impl) Debug(msg string, args ...any) {
 	log.Printf("[DEBUG] %s\n", fmt.Sprintf(msg, args...))
 }
 
+// Info prints info message
+func (i *Impl) Info(msg string, args ...any) {
+	log.Printf("[INFO] %s\n", fmt.Sprintf(msg, args...))
+}
+
+// Warn prints warning message
+func (i *Impl) Warn(msg string, args ...any) {
+	log.Printf("[WARN] %s\n", fmt.Sprintf(msg, args...))
+}
+
 // Error prints error message
 func (i *Impl) Error(err error) {
 	log.Printf("[ERROR] %+v\n", err)
@@ -0,0 +1,106 @@
+package logruslogger
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/corbado/webhook-go/pkg/logger"
+)
+
+// Logger is a logger backed by logrus, it implements logger.Logger and logger.Leveled (fields become logrus
+// fields).
+type Logger struct {
+	entry *logrus.Entry
+}
+
+var _ logger.Logger = &Logger{}
+var _ logger.Leveled = &Logger{}
+
+// New returns new logger instance which logs through given logrus logger.
+func New(logrusLogger *logrus.Logger) (*Logger, error) {
+	if logrusLogger == nil {
+		return nil, errors.New("empty parameter logrusLogger")
+	}
+
+	return &Logger{entry: logrus.NewEntry(logrusLogger)}, nil
+}
+
+// Debug prints debug message
+func (l *Logger) Debug(msg string, args ...any) {
+	l.entry.Debug(fmt.Sprintf(msg, args...))
+}
+
+// Info prints info message
+func (l *Logger) Info(msg string, args ...any) {
+	l.entry.Info(fmt.Sprintf(msg, args...))
+}
+
+// Warn prints warning message
+func (l *Logger) Warn(msg string, args ...any) {
+	l.entry.Warn(fmt.Sprintf(msg, args...))
+}
+
+// Error prints error message
+func (l *Logger) Error(err error) {
+	if err == nil {
+		l.Log(logger.LevelError, "<nil>")
+
+		return
+	}
+
+	l.Log(logger.LevelError, err.Error(), logger.Err(err))
+}
+
+// Log logs given message with given fields. Error values are logged with their message, the stack trace of
+// errors created with github.com/pkg/errors is added as field "stack".
+func (l *Logger) Log(level logger.Level, msg string, fields ...logger.Field) {
+	logrusLevel := toLogrusLevel(level)
+	if !l.entry.Logger.IsLevelEnabled(logrusLevel) {
+		return
+	}
+
+	l.entry.WithFields(logrusFields(fields)).Log(logrusLevel, msg)
+}
+
+// With returns a logger which adds given fields to all messages.
+func (l *Logger) With(fields ...logger.Field) logger.Leveled {
+	return &Logger{entry: l.entry.WithFields(logrusFields(fields))}
+}
+
+var logrusLevels = map[logger.Level]logrus.Level{
+	logger.LevelDebug: logrus.DebugLevel,
+	logger.LevelInfo:  logrus.InfoLevel,
+	logger.LevelWarn:  logrus.WarnLevel,
+	logger.LevelError: logrus.ErrorLevel,
+}
+
+// toLogrusLevel returns the logrus level of given level, unknown levels are logged as errors (the zero value
+// of logrus.Level is logrus.PanicLevel, which panics).
+func toLogrusLevel(level logger.Level) logrus.Level {
+	if logrusLevel, ok := logrusLevels[level]; ok {
+		return logrusLevel
+	}
+
+	return logrus.ErrorLevel
+}
+
+func logrusFields(fields []logger.Field) logrus.Fields {
+	logrusFields := make(logrus.Fields, len(fields))
+	for _, field := range fields {
+		err, ok := field.Value.(error)
+		if !ok {
+			logrusFields[field.Key] = field.Value
+
+			continue
+		}
+
+		logrusFields[field.Key] = err.Error()
+		if stack := logger.StackTrace(err); stack != "" {
+			logrusFields["stack"] = stack
+		}
+	}
+
+	return logrusFields
+}
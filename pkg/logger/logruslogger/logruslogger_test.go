@@ -0,0 +1,72 @@
+package logruslogger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/logger"
+	"github.com/corbado/webhook-go/pkg/logger/logruslogger"
+)
+
+func TestNew(t *testing.T) {
+	l, err := logruslogger.New(nil)
+	assert.ErrorContains(t, err, "empty parameter logrusLogger")
+	assert.Nil(t, l)
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(&buf)
+	logrusLogger.SetLevel(logrus.InfoLevel)
+	logrusLogger.SetFormatter(&logrus.JSONFormatter{DisableTimestamp: true})
+
+	l, err := logruslogger.New(logrusLogger)
+	require.NoError(t, err)
+
+	l.Debug("filtered %s", "out")
+	l.Info("Listening on %s", ":8080")
+	l.Warn("Slow callback")
+	l.With(logger.F("action", "passwordVerify")).Log(logger.LevelWarn, "Authentication failed", logger.F("clientIP", "192.0.2.1"), logger.F("status", 401))
+	l.Error(errors.Wrap(errors.New("connection refused"), "lookup failed"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+
+	assert.Equal(t, `{"level":"info","msg":"Listening on :8080"}`, lines[0])
+	assert.Equal(t, `{"level":"warning","msg":"Slow callback"}`, lines[1])
+	assert.Equal(t, `{"action":"passwordVerify","clientIP":"192.0.2.1","level":"warning","msg":"Authentication failed","status":401}`, lines[2])
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &record))
+	assert.Equal(t, "error", record["level"])
+	assert.Equal(t, "lookup failed: connection refused", record["msg"])
+	assert.Equal(t, "lookup failed: connection refused", record["error"])
+	assert.Contains(t, record["stack"], "logruslogger_test.TestLogger")
+}
+
+func TestLoggerUnknownLevelAndNilError(t *testing.T) {
+	var buf bytes.Buffer
+
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(&buf)
+	logrusLogger.SetFormatter(&logrus.JSONFormatter{DisableTimestamp: true})
+
+	l, err := logruslogger.New(logrusLogger)
+	require.NoError(t, err)
+
+	assert.NotPanics(t, func() {
+		l.Log(logger.Level(42), "Unknown level")
+		l.Error(nil)
+	})
+
+	assert.Equal(t, `{"level":"error","msg":"Unknown level"}`+"\n"+`{"level":"error","msg":"\u003cnil\u003e"}`+"\n", buf.String())
+}
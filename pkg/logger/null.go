@@ -4,6 +4,7 @@ type Null struct {
 }
 
 var _ Logger = &Null{}
+var _ Leveled = &Null{}
 
 // NewNull returns new null logger instance which can be used in unit tests for example.
 func NewNull() *Null {
@@ -17,3 +18,12 @@ func (n *Null) Debug(msg string, args ...any) {
 // Error prints error message
 func (n *Null) Error(_ error) {
 }
+
+// Log prints nothing
+func (n *Null) Log(_ Level, _ string, _ ...Field) {
+}
+
+// With returns the null logger itself
+func (n *Null) With(_ ...Field) Leveled {
+	return n
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/corbado/webhook-go/pkg/redact"
+)
+
+// Redacting masks secrets (passwords, tokens, Authorization headers, see redact.String()) in messages, errors
+// and fields before passing them to the wrapped logger. Values of fields with sensitive keys (see
+// redact.SensitiveKey()) are masked completely.
+type Redacting struct {
+	logger  Logger
+	leveled Leveled
+}
+
+var _ Logger = &Redacting{}
+var _ Leveled = &Redacting{}
+
+// NewRedacting returns new logger instance which redacts secrets before passing messages to given logger.
+func NewRedacting(logger Logger) *Redacting {
+	return &Redacting{
+		logger:  logger,
+		leveled: NewLeveled(logger),
+	}
+}
+
+// Debug prints debug message
+func (r *Redacting) Debug(msg string, args ...any) {
+	r.logger.Debug("%s", redact.String(fmt.Sprintf(msg, args...)))
+}
+
+// Info prints info message
+func (r *Redacting) Info(msg string, args ...any) {
+	r.leveled.Log(LevelInfo, redact.String(fmt.Sprintf(msg, args...)))
+}
+
+// Warn prints warning message
+func (r *Redacting) Warn(msg string, args ...any) {
+	r.leveled.Log(LevelWarn, redact.String(fmt.Sprintf(msg, args...)))
+}
+
+// Error prints error message
+func (r *Redacting) Error(err error) {
+	if err == nil {
+		r.logger.Error(nil)
+
+		return
+	}
+
+	r.logger.Error(&redactedError{cause: err})
+}
+
+// Log logs given message with given fields.
+func (r *Redacting) Log(level Level, msg string, fields ...Field) {
+	r.leveled.Log(level, redact.String(msg), redactFields(fields)...)
+}
+
+// With returns a logger which adds given fields to all messages.
+func (r *Redacting) With(fields ...Field) Leveled {
+	return &Redacting{
+		logger:  r.logger,
+		leveled: r.leveled.With(redactFields(fields)...),
+	}
+}
+
+func redactFields(fields []Field) []Field {
+	redacted := make([]Field, len(fields))
+	for i, field := range fields {
+		redacted[i] = field
+
+		if redact.SensitiveKey(field.Key) {
+			redacted[i].Value = redact.Mask
+
+			continue
+		}
+
+		switch value := field.Value.(type) {
+		case string:
+			redacted[i].Value = redact.String(value)
+		case error:
+			redacted[i].Value = &redactedError{cause: value}
+		}
+	}
+
+	return redacted
+}
+
+// redactedError redacts the message of its cause, the cause can still be unwrapped (to get its stack trace
+// for example).
+type redactedError struct {
+	cause error
+}
+
+func (e *redactedError) Error() string {
+	return redact.String(e.cause.Error())
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.cause
+}
+
+// Format formats the error like its cause (%+v includes the stack trace of errors created with
+// github.com/pkg/errors) but redacted.
+func (e *redactedError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = fmt.Fprint(s, redact.String(fmt.Sprintf("%+v", e.cause)))
+
+		return
+	}
+
+	_, _ = fmt.Fprint(s, e.Error())
+}
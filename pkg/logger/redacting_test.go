@@ -0,0 +1,66 @@
+package logger_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/corbado/webhook-go/pkg/logger"
+)
+
+func TestRedacting(t *testing.T) {
+	r := &recorder{}
+	l := logger.NewRedacting(r)
+
+	l.Debug("Request body: %s", `{"username":"jane","password":"secretPassword"}`)
+	l.Info("Header Authorization: Basic %s", "d2ViaG9vazpzZWNyZXQ=")
+	l.With(logger.F("webhookPassword", "secretPassword")).Log(logger.LevelWarn, "Login failed", logger.F("query", "user=jane&token=abc123"))
+
+	err := errors.Wrap(errors.New(`decoding {"password":"secretPassword"} failed`), "callback failed")
+	l.Error(err)
+	l.Log(logger.LevelError, "Callback failed", logger.Err(err))
+
+	assert.Equal(t, []string{
+		`DEBUG Request body: {"username":"jane","password":"[REDACTED]"}`,
+		`DEBUG [INFO] Header Authorization: Basic [REDACTED]`,
+		`DEBUG [WARN] Login failed webhookPassword=[REDACTED] query="user=jane&token=[REDACTED]"`,
+		`ERROR callback failed: decoding {"password":"[REDACTED]"} failed`,
+		`ERROR Callback failed: callback failed: decoding {"password":"[REDACTED]"} failed`,
+	}, r.lines)
+}
+
+func TestRedactingStackTrace(t *testing.T) {
+	var logged error
+	l := logger.NewRedacting(errorRecorder(func(err error) {
+		logged = err
+	}))
+
+	cause := errors.New(`token=abc123 rejected`)
+	l.Error(cause)
+
+	assert.ErrorIs(t, logged, cause)
+	assert.Contains(t, fmt.Sprintf("%+v", logged), "token=[REDACTED] rejected")
+	assert.Contains(t, fmt.Sprintf("%+v", logged), "logger_test.TestRedactingStackTrace")
+	assert.NotContains(t, fmt.Sprintf("%+v", logged), "abc123")
+}
+
+type errorRecorder func(err error)
+
+func (e errorRecorder) Debug(_ string, _ ...any) {
+}
+
+func (e errorRecorder) Error(err error) {
+	e(err)
+}
+
+func TestRedactingNilError(t *testing.T) {
+	logged := errors.New("not called")
+	l := logger.NewRedacting(errorRecorder(func(err error) {
+		logged = err
+	}))
+
+	l.Error(nil)
+	assert.Nil(t, logged)
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Slog is a logger backed by log/slog, it implements Logger and Leveled (fields become slog attributes).
+type Slog struct {
+	logger *slog.Logger
+}
+
+var _ Logger = &Slog{}
+var _ Leveled = &Slog{}
+
+// NewSlog returns new logger instance which logs through given slog logger (slog.Default() if nil).
+func NewSlog(logger *slog.Logger) *Slog {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Slog{logger: logger}
+}
+
+// Debug prints debug message
+func (s *Slog) Debug(msg string, args ...any) {
+	s.logger.Debug(fmt.Sprintf(msg, args...))
+}
+
+// Info prints info message
+func (s *Slog) Info(msg string, args ...any) {
+	s.logger.Info(fmt.Sprintf(msg, args...))
+}
+
+// Warn prints warning message
+func (s *Slog) Warn(msg string, args ...any) {
+	s.logger.Warn(fmt.Sprintf(msg, args...))
+}
+
+// Error prints error message
+func (s *Slog) Error(err error) {
+	if err == nil {
+		s.Log(LevelError, "<nil>")
+
+		return
+	}
+
+	s.Log(LevelError, err.Error(), Err(err))
+}
+
+// Log logs given message with given fields as attributes. Error values are logged with their message, the
+// stack trace of errors created with github.com/pkg/errors is added as attribute "stack".
+func (s *Slog) Log(level Level, msg string, fields ...Field) {
+	slogLevel := toSlogLevel(level)
+	if !s.logger.Enabled(context.Background(), slogLevel) {
+		return
+	}
+
+	s.logger.LogAttrs(context.Background(), slogLevel, msg, slogAttrs(fields)...)
+}
+
+// With returns a logger which adds given fields to all messages.
+func (s *Slog) With(fields ...Field) Leveled {
+	attrs := slogAttrs(fields)
+
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+
+	return &Slog{logger: s.logger.With(args...)}
+}
+
+var slogLevels = map[Level]slog.Level{
+	LevelDebug: slog.LevelDebug,
+	LevelInfo:  slog.LevelInfo,
+	LevelWarn:  slog.LevelWarn,
+	LevelError: slog.LevelError,
+}
+
+// toSlogLevel returns the slog level of given level, unknown levels are logged as errors (to not lose them).
+func toSlogLevel(level Level) slog.Level {
+	if slogLevel, ok := slogLevels[level]; ok {
+		return slogLevel
+	}
+
+	return slog.LevelError
+}
+
+func slogAttrs(fields []Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		err, ok := field.Value.(error)
+		if !ok {
+			attrs = append(attrs, slog.Any(field.Key, field.Value))
+
+			continue
+		}
+
+		attrs = append(attrs, slog.String(field.Key, err.Error()))
+		if stack := StackTrace(err); stack != "" {
+			attrs = append(attrs, slog.String("stack", stack))
+		}
+	}
+
+	return attrs
+}
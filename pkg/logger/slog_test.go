@@ -0,0 +1,65 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/logger"
+)
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.NewSlog(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return attr
+		},
+	})))
+
+	l.Debug("filtered %s", "out")
+	l.Info("Listening on %s", ":8080")
+	l.With(logger.F("action", "passwordVerify")).Log(logger.LevelWarn, "Authentication failed", logger.F("clientIP", "192.0.2.1"))
+	l.Error(errors.New("connection refused"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	assert.Equal(t, `{"level":"INFO","msg":"Listening on :8080"}`, lines[0])
+	assert.Equal(t, `{"level":"WARN","msg":"Authentication failed","action":"passwordVerify","clientIP":"192.0.2.1"}`, lines[1])
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &record))
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, "connection refused", record["msg"])
+	assert.Equal(t, "connection refused", record["error"])
+	assert.Contains(t, record["stack"], "logger_test.TestSlog")
+}
+
+func TestSlogUnknownLevelAndNilError(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.NewSlog(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return attr
+		},
+	})))
+
+	l.Log(logger.Level(42), "Unknown level")
+	l.Error(nil)
+
+	assert.Equal(t, `{"level":"ERROR","msg":"Unknown level"}`+"\n"+`{"level":"ERROR","msg":"<nil>"}`+"\n", buf.String())
+}
@@ -0,0 +1,30 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+// StackTrace returns the stack trace of the innermost error in the chain of given error which was created
+// with github.com/pkg/errors (empty if there is none).
+func StackTrace(err error) string {
+	var stack errors.StackTrace
+	for err != nil {
+		if tracer, ok := err.(stackTracer); ok {
+			stack = tracer.StackTrace()
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	if stack == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%+v", stack)
+}
@@ -0,0 +1,103 @@
+package zaplogger
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/corbado/webhook-go/pkg/logger"
+)
+
+// Logger is a logger backed by zap, it implements logger.Logger and logger.Leveled (fields become zap
+// fields).
+type Logger struct {
+	logger *zap.Logger
+}
+
+var _ logger.Logger = &Logger{}
+var _ logger.Leveled = &Logger{}
+
+// New returns new logger instance which logs through given zap logger.
+func New(zapLogger *zap.Logger) (*Logger, error) {
+	if zapLogger == nil {
+		return nil, errors.New("empty parameter zapLogger")
+	}
+
+	return &Logger{logger: zapLogger}, nil
+}
+
+// Debug prints debug message
+func (l *Logger) Debug(msg string, args ...any) {
+	l.logger.Debug(fmt.Sprintf(msg, args...))
+}
+
+// Info prints info message
+func (l *Logger) Info(msg string, args ...any) {
+	l.logger.Info(fmt.Sprintf(msg, args...))
+}
+
+// Warn prints warning message
+func (l *Logger) Warn(msg string, args ...any) {
+	l.logger.Warn(fmt.Sprintf(msg, args...))
+}
+
+// Error prints error message
+func (l *Logger) Error(err error) {
+	if err == nil {
+		l.Log(logger.LevelError, "<nil>")
+
+		return
+	}
+
+	l.Log(logger.LevelError, err.Error(), logger.Err(err))
+}
+
+// Log logs given message with given fields. Error values are logged with their message, the stack trace of
+// errors created with github.com/pkg/errors is added as field "stack".
+func (l *Logger) Log(level logger.Level, msg string, fields ...logger.Field) {
+	if entry := l.logger.Check(zapLevel(level), msg); entry != nil {
+		entry.Write(zapFields(fields)...)
+	}
+}
+
+// With returns a logger which adds given fields to all messages.
+func (l *Logger) With(fields ...logger.Field) logger.Leveled {
+	return &Logger{logger: l.logger.With(zapFields(fields)...)}
+}
+
+var zapLevels = map[logger.Level]zapcore.Level{
+	logger.LevelDebug: zapcore.DebugLevel,
+	logger.LevelInfo:  zapcore.InfoLevel,
+	logger.LevelWarn:  zapcore.WarnLevel,
+	logger.LevelError: zapcore.ErrorLevel,
+}
+
+// zapLevel returns the zap level of given level, unknown levels are logged as errors (to not lose them).
+func zapLevel(level logger.Level) zapcore.Level {
+	if zapLevel, ok := zapLevels[level]; ok {
+		return zapLevel
+	}
+
+	return zapcore.ErrorLevel
+}
+
+func zapFields(fields []logger.Field) []zap.Field {
+	zapFields := make([]zap.Field, 0, len(fields))
+	for _, field := range fields {
+		err, ok := field.Value.(error)
+		if !ok {
+			zapFields = append(zapFields, zap.Any(field.Key, field.Value))
+
+			continue
+		}
+
+		zapFields = append(zapFields, zap.String(field.Key, err.Error()))
+		if stack := logger.StackTrace(err); stack != "" {
+			zapFields = append(zapFields, zap.String("stack", stack))
+		}
+	}
+
+	return zapFields
+}
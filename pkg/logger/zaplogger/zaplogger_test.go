@@ -0,0 +1,66 @@
+package zaplogger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/corbado/webhook-go/pkg/logger"
+	"github.com/corbado/webhook-go/pkg/logger/zaplogger"
+)
+
+func TestNew(t *testing.T) {
+	l, err := zaplogger.New(nil)
+	assert.ErrorContains(t, err, "empty parameter zapLogger")
+	assert.Nil(t, l)
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = ""
+
+	l, err := zaplogger.New(zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(&buf), zapcore.InfoLevel)))
+	require.NoError(t, err)
+
+	l.Debug("filtered %s", "out")
+	l.Info("Listening on %s", ":8080")
+	l.Warn("Slow callback")
+	l.With(logger.F("action", "passwordVerify")).Log(logger.LevelWarn, "Authentication failed", logger.F("clientIP", "192.0.2.1"), logger.F("status", 401))
+	l.Error(errors.Wrap(errors.New("connection refused"), "lookup failed"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 4)
+
+	assert.Equal(t, `{"level":"info","msg":"Listening on :8080"}`, lines[0])
+	assert.Equal(t, `{"level":"warn","msg":"Slow callback"}`, lines[1])
+	assert.Equal(t, `{"level":"warn","msg":"Authentication failed","action":"passwordVerify","clientIP":"192.0.2.1","status":401}`, lines[2])
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &record))
+	assert.Equal(t, "error", record["level"])
+	assert.Equal(t, "lookup failed: connection refused", record["msg"])
+	assert.Equal(t, "lookup failed: connection refused", record["error"])
+	assert.Contains(t, record["stack"], "zaplogger_test.TestLogger")
+}
+
+func TestLoggerUnknownLevelAndNilError(t *testing.T) {
+	var buf bytes.Buffer
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = ""
+
+	l, err := zaplogger.New(zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(&buf), zapcore.InfoLevel)))
+	require.NoError(t, err)
+
+	l.Log(logger.Level(42), "Unknown level")
+	l.Error(nil)
+
+	assert.Equal(t, `{"level":"error","msg":"Unknown level"}`+"\n"+`{"level":"error","msg":"<nil>"}`+"\n", buf.String())
+}
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/corbado/webhook-go/pkg/hooks"
+)
+
+// DefaultNamespace is the namespace of the metrics if none is given.
+const DefaultNamespace = "corbado_webhook"
+
+// Metrics records metrics of webhook requests, add the hooks returned by Hooks() to the webhook (see
+// Builder.AddHooks()). Metrics implements prometheus.Collector, so it can be registered with an existing
+// registry, or it can be exposed with its own handler (see Handler()).
+type Metrics struct {
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	callbackDuration *prometheus.HistogramVec
+	callbackErrors   *prometheus.CounterVec
+	authFailures     prometheus.Counter
+	lockouts         prometheus.Counter
+	panics           *prometheus.CounterVec
+}
+
+var _ prometheus.Collector = &Metrics{}
+
+// New returns new metrics instance with given namespace (DefaultNamespace if empty).
+func New(namespace string) *Metrics {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	return &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Number of webhook requests by action, HTTP status code and result.",
+		}, []string{"action", "status", "result"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of webhook requests by action.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"action"}),
+		callbackDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "callback_duration_seconds",
+			Help:      "Duration of callbacks (action handlers) by action.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"action"}),
+		callbackErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "callback_errors_total",
+			Help:      "Number of callbacks (action handlers) which returned an error by action.",
+		}, []string{"action"}),
+		authFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_failures_total",
+			Help:      "Number of webhook requests which failed authentication.",
+		}),
+		lockouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "lockouts_total",
+			Help:      "Number of client addresses locked after repeated authentication failures.",
+		}),
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "panics_total",
+			Help:      "Number of recovered panics by action.",
+		}, []string{"action"}),
+	}
+}
+
+// Hooks returns the hooks which record the metrics.
+func (m *Metrics) Hooks() *hooks.Hooks {
+	return &hooks.Hooks{
+		OnPanic: func(_ context.Context, action string, _ error) {
+			m.panics.WithLabelValues(action).Inc()
+		},
+		OnAuthFailure: func(_ context.Context, _ netip.Addr) {
+			m.authFailures.Inc()
+		},
+		OnLockout: func(_ context.Context, _ netip.Addr, _ time.Duration) {
+			m.lockouts.Inc()
+		},
+		OnHandled: func(_ context.Context, action string, duration time.Duration, err error) {
+			m.callbackDuration.WithLabelValues(action).Observe(duration.Seconds())
+
+			if err != nil {
+				m.callbackErrors.WithLabelValues(action).Inc()
+			}
+		},
+		OnRequest: func(_ context.Context, request *hooks.Request) {
+			m.requests.WithLabelValues(request.Action, strconv.Itoa(request.StatusCode), request.Outcome).Inc()
+			m.requestDuration.WithLabelValues(request.Action).Observe(request.Duration.Seconds())
+		},
+	}
+}
+
+// Describe sends the descriptors of all metrics to given channel (implements prometheus.Collector).
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range m.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+// Collect sends all metrics to given channel (implements prometheus.Collector).
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range m.collectors() {
+		collector.Collect(ch)
+	}
+}
+
+// Handler returns a handler which exposes the metrics in the Prometheus exposition format, it can be
+// mounted next to the webhook (only the webhook metrics are exposed, register the metrics with an existing
+// registry to expose them together with other metrics).
+func (m *Metrics) Handler() (http.Handler, error) {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(m); err != nil {
+		return nil, err
+	}
+
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), nil
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.requests,
+		m.requestDuration,
+		m.callbackDuration,
+		m.callbackErrors,
+		m.authFailures,
+		m.lockouts,
+		m.panics,
+	}
+}
@@ -0,0 +1,105 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/action"
+	"github.com/corbado/webhook-go/pkg/callback"
+	"github.com/corbado/webhook-go/pkg/core"
+	"github.com/corbado/webhook-go/pkg/credential"
+	"github.com/corbado/webhook-go/pkg/dto/authmethodsrequest"
+	"github.com/corbado/webhook-go/pkg/dto/authmethodsresponse"
+	"github.com/corbado/webhook-go/pkg/dto/passwordverifyrequest"
+	"github.com/corbado/webhook-go/pkg/hooks"
+	"github.com/corbado/webhook-go/pkg/logger"
+	"github.com/corbado/webhook-go/pkg/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	authMethodsHandler, err := action.NewAuthMethods(func(_ context.Context, req *authmethodsrequest.DTO) (*callback.AuthMethodsResult, error) {
+		if req.Data.Username == "failing" {
+			return nil, errors.New("database unavailable")
+		}
+
+		return &callback.AuthMethodsResult{Status: authmethodsresponse.StatusExists}, nil
+	})
+	require.NoError(t, err)
+
+	passwordVerifyHandler, err := action.NewPasswordVerify(func(_ context.Context, req *passwordverifyrequest.DTO) (*callback.PasswordVerifyResult, error) {
+		return &callback.PasswordVerifyResult{Success: req.Data.Password.Value() == "correct"}, nil
+	})
+	require.NoError(t, err)
+
+	cred, err := credential.New(credential.DefaultLabel, "webhookUsername", "webhookPassword", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	m := metrics.New("")
+
+	c, err := core.New(&core.Config{
+		Logger:      logger.NewNull(),
+		Credentials: []*credential.Credential{cred},
+		Actions: map[string]action.Handler{
+			action.AuthMethods:    authMethodsHandler,
+			action.PasswordVerify: passwordVerifyHandler,
+		},
+		Hooks: []*hooks.Hooks{m.Hooks()},
+	})
+	require.NoError(t, err)
+
+	handle := func(actionName string, password string, data string) {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		r.SetBasicAuth("webhookUsername", password)
+		r.Header.Set("X-Corbado-Action", actionName)
+
+		c.Handle(&core.Request{
+			Method: r.Method,
+			Header: r.Header,
+			Body:   strings.NewReader(`{"id":"who-1","projectID":"pro-1","action":"` + actionName + `","data":` + data + `}`),
+		})
+	}
+
+	handle("authMethods", "webhookPassword", `{"username":"jane"}`)
+	handle("authMethods", "webhookPassword", `{"username":"jane"}`)
+	handle("authMethods", "webhookPassword", `{"username":"failing"}`)
+	handle("passwordVerify", "webhookPassword", `{"username":"jane","password":"correct"}`)
+	handle("passwordVerify", "webhookPassword", `{"username":"jane","password":"wrong"}`)
+	handle("passwordVerify", "wrong", `{"username":"jane","password":"correct"}`)
+	handle("unknownAction", "webhookPassword", `{}`)
+
+	handler, err := m.Handler()
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	body, err := io.ReadAll(rr.Body)
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		`corbado_webhook_requests_total{action="authMethods",result="exists",status="200"} 2`,
+		`corbado_webhook_requests_total{action="authMethods",result="error",status="500"} 1`,
+		`corbado_webhook_requests_total{action="passwordVerify",result="success",status="200"} 1`,
+		`corbado_webhook_requests_total{action="passwordVerify",result="failure",status="200"} 1`,
+		`corbado_webhook_requests_total{action="",result="error",status="401"} 1`,
+		`corbado_webhook_requests_total{action="",result="error",status="400"} 1`,
+		`corbado_webhook_callback_duration_seconds_count{action="authMethods"} 3`,
+		`corbado_webhook_callback_duration_seconds_count{action="passwordVerify"} 2`,
+		`corbado_webhook_callback_errors_total{action="authMethods"} 1`,
+		`corbado_webhook_auth_failures_total 1`,
+	} {
+		assert.Contains(t, string(body), line+"\n")
+	}
+}
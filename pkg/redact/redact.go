@@ -0,0 +1,91 @@
+package redact
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Mask replaces redacted values.
+const Mask = "[REDACTED]"
+
+// Secret is a string which is masked when it is formatted (%v, %+v, %#v, %s, %q) or marshaled, so it does not
+// leak into log output. Use Value() to access the plaintext.
+type Secret string
+
+// String returns the mask (implements fmt.Stringer).
+func (s Secret) String() string {
+	return Mask
+}
+
+// GoString returns the quoted mask (implements fmt.GoStringer).
+func (s Secret) GoString() string {
+	return `"` + Mask + `"`
+}
+
+// MarshalText returns the mask (implements encoding.TextMarshaler, used by JSON encoding and structured
+// loggers).
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(Mask), nil
+}
+
+// Value returns the plaintext of the secret.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "apikey", "api_key"}
+
+// SensitiveKey returns if given key (of a log field or a JSON property) names a sensitive value.
+func SensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var patterns = []struct {
+	regexp      *regexp.Regexp
+	replacement string
+}{
+	// JSON properties like "password":"..."
+	{regexp.MustCompile(`(?i)("[^"]*(?:password|secret|token|apikey|api_key)[^"]*"\s*:\s*")(?:[^"\\]|\\.)*(")`), "${1}" + Mask + "${2}"},
+
+	// Authorization headers like Authorization: Basic ...
+	{regexp.MustCompile(`(?i)(authorization"?\s*[:=]\s*"?(?:basic|bearer)\s+)[^\s",]+`), "${1}" + Mask},
+
+	// key/value pairs like password=...
+	{regexp.MustCompile(`(?i)(\b\w*(?:password|secret|token|apikey|api_key)\w*=)[^\s&,]+`), "${1}" + Mask},
+}
+
+// String masks the values of sensitive JSON properties, Authorization headers and key/value pairs in given
+// string.
+func String(s string) string {
+	for _, pattern := range patterns {
+		s = pattern.regexp.ReplaceAllString(s, pattern.replacement)
+	}
+
+	return s
+}
+
+// JSONError replaces JSON decoding errors which can contain parts of the decoded input (syntax and type
+// errors) with errors which only contain the position or field. Other errors are returned as is.
+func JSONError(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return errors.Errorf("invalid JSON syntax at offset %d", syntaxErr.Offset)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return errors.Errorf("invalid type of field '%s' at offset %d (expected %s)", typeErr.Field, typeErr.Offset, typeErr.Type)
+	}
+
+	return err
+}
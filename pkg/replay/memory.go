@@ -0,0 +1,120 @@
+package replay
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultCapacity is the number of request ids the in-memory store remembers if none is configured.
+const DefaultCapacity = 10000
+
+type MemoryStore struct {
+	capacity int
+	mutex    sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+var _ Store = &MemoryStore{}
+
+type memoryEntry struct {
+	key       string
+	entry     *Entry
+	expiresAt time.Time
+}
+
+// NewMemoryStore returns new in-memory store which remembers up to capacity request ids (least recently
+// used ones are evicted first). Use zero capacity for DefaultCapacity.
+func NewMemoryStore(capacity int) (*MemoryStore, error) {
+	if capacity < 0 {
+		return nil, errors.Errorf("invalid parameter capacity (%d)", capacity)
+	}
+
+	if capacity == 0 {
+		capacity = DefaultCapacity
+	}
+
+	return &MemoryStore{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}, nil
+}
+
+// Reserve atomically marks given key as seen for given TTL.
+func (m *MemoryStore) Reserve(_ context.Context, key string, ttl time.Duration) (*Entry, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+
+	if element, exists := m.entries[key]; exists {
+		stored := element.Value.(*memoryEntry)
+		if now.Before(stored.expiresAt) {
+			m.order.MoveToFront(element)
+
+			return stored.entry, false, nil
+		}
+
+		m.remove(element)
+	}
+
+	for m.order.Len() >= m.capacity {
+		m.remove(m.order.Back())
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryEntry{
+		key:       key,
+		entry:     &Entry{},
+		expiresAt: now.Add(ttl),
+	})
+
+	return nil, true, nil
+}
+
+// Complete stores given entry for given reserved key.
+func (m *MemoryStore) Complete(_ context.Context, key string, entry *Entry, ttl time.Duration) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	element, exists := m.entries[key]
+	if !exists {
+		// evicted in the meantime, remember it again
+		element = m.order.PushFront(&memoryEntry{key: key})
+		m.entries[key] = element
+	}
+
+	stored := element.Value.(*memoryEntry)
+	stored.entry = entry
+	stored.expiresAt = m.now().Add(ttl)
+	m.order.MoveToFront(element)
+
+	for m.order.Len() > m.capacity {
+		m.remove(m.order.Back())
+	}
+
+	return nil
+}
+
+// Release removes given key.
+func (m *MemoryStore) Release(_ context.Context, key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if element, exists := m.entries[key]; exists {
+		m.remove(element)
+	}
+
+	return nil
+}
+
+func (m *MemoryStore) remove(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryEntry).key)
+}
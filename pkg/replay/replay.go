@@ -0,0 +1,102 @@
+package replay
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Mode defines how duplicate requests (requests with an id which was already seen) are handled.
+type Mode int
+
+const (
+	// ModeReject rejects duplicate requests with status code 409.
+	ModeReject Mode = iota
+
+	// ModeReplay sends the response of the first request again (idempotent retries). Duplicates which arrive
+	// while the first request is still being handled are rejected with status code 409.
+	ModeReplay
+)
+
+// Entry is the stored state of a seen request id.
+type Entry struct {
+	// Done is false while the first request is still being handled.
+	Done       bool
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Store stores seen request ids, implementations must be safe for concurrent use (and can be shared between
+// replicas, for example backed by Redis).
+type Store interface {
+	// Reserve atomically marks given key as seen for given TTL. If the key was already seen its entry is
+	// returned and reserved is false.
+	Reserve(ctx context.Context, key string, ttl time.Duration) (entry *Entry, reserved bool, err error)
+
+	// Complete stores given entry (response of the first request) for given reserved key.
+	Complete(ctx context.Context, key string, entry *Entry, ttl time.Duration) error
+
+	// Release removes given reserved key so the request can be retried (used if the first request failed).
+	Release(ctx context.Context, key string) error
+}
+
+// Guard remembers the ids of handled requests to detect duplicates.
+type Guard struct {
+	store Store
+	ttl   time.Duration
+	mode  Mode
+}
+
+// NewGuard returns new replay guard which remembers request ids in given store for given TTL.
+func NewGuard(store Store, ttl time.Duration, mode Mode) (*Guard, error) {
+	if store == nil {
+		return nil, errors.New("empty parameter store")
+	}
+
+	if ttl <= 0 {
+		return nil, errors.Errorf("invalid parameter ttl (%s)", ttl)
+	}
+
+	if mode != ModeReject && mode != ModeReplay {
+		return nil, errors.Errorf("invalid parameter mode (%d)", mode)
+	}
+
+	return &Guard{
+		store: store,
+		ttl:   ttl,
+		mode:  mode,
+	}, nil
+}
+
+// NewMemoryGuard returns new replay guard backed by an in-memory LRU store with default capacity.
+func NewMemoryGuard(ttl time.Duration, mode Mode) (*Guard, error) {
+	store, err := NewMemoryStore(DefaultCapacity)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewGuard(store, ttl, mode)
+}
+
+// Mode returns the mode of the guard.
+func (g *Guard) Mode() Mode {
+	return g.mode
+}
+
+// Reserve marks given key as seen, see Store.Reserve().
+func (g *Guard) Reserve(ctx context.Context, key string) (*Entry, bool, error) {
+	return g.store.Reserve(ctx, key, g.ttl)
+}
+
+// Complete stores given entry for given key, see Store.Complete().
+func (g *Guard) Complete(ctx context.Context, key string, entry *Entry) error {
+	return g.store.Complete(ctx, key, entry, g.ttl)
+}
+
+// Release removes given key, see Store.Release().
+func (g *Guard) Release(ctx context.Context, key string) error {
+	return g.store.Release(ctx, key)
+}
@@ -0,0 +1,85 @@
+package replay_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/replay"
+)
+
+func TestNewGuard(t *testing.T) {
+	store, err := replay.NewMemoryStore(0)
+	require.NoError(t, err)
+
+	guard, err := replay.NewGuard(nil, time.Minute, replay.ModeReject)
+	assert.ErrorContains(t, err, "empty parameter store")
+	assert.Nil(t, guard)
+
+	guard, err = replay.NewGuard(store, 0, replay.ModeReject)
+	assert.ErrorContains(t, err, "invalid parameter ttl")
+	assert.Nil(t, guard)
+
+	guard, err = replay.NewGuard(store, time.Minute, replay.ModeReplay)
+	assert.NoError(t, err)
+	assert.Equal(t, replay.ModeReplay, guard.Mode())
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := replay.NewMemoryStore(2)
+	require.NoError(t, err)
+
+	entry, reserved, err := store.Reserve(ctx, "who-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Nil(t, entry)
+
+	entry, reserved, err = store.Reserve(ctx, "who-1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.False(t, entry.Done)
+
+	require.NoError(t, store.Complete(ctx, "who-1", &replay.Entry{Done: true, StatusCode: 200, Body: []byte("body")}, time.Minute))
+
+	entry, reserved, err = store.Reserve(ctx, "who-1", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.True(t, entry.Done)
+	assert.Equal(t, []byte("body"), entry.Body)
+
+	// release allows retries
+	_, reserved, err = store.Reserve(ctx, "who-2", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	require.NoError(t, store.Release(ctx, "who-2"))
+
+	_, reserved, err = store.Reserve(ctx, "who-2", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	// capacity is 2, so least recently used who-1 is evicted
+	_, reserved, err = store.Reserve(ctx, "who-3", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	_, reserved, err = store.Reserve(ctx, "who-1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	// expiry
+	_, reserved, err = store.Reserve(ctx, "who-4", 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, reserved, err = store.Reserve(ctx, "who-4", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+}
@@ -0,0 +1,93 @@
+package responseid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Request holds the information about a webhook request which is available to generators.
+type Request struct {
+	Action    string
+	ID        string
+	ProjectID string
+}
+
+// Generator generates the responseID for given request. The responseID shows up in the webhook log in the
+// developer panel and in the log output of the webhook request.
+type Generator func(req *Request) (string, error)
+
+type contextKey struct{}
+
+// NewContext returns a copy of given context carrying given responseID.
+func NewContext(ctx context.Context, responseID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, responseID)
+}
+
+// FromContext returns the responseID carried by given context (empty string if none).
+func FromContext(ctx context.Context) string {
+	responseID, _ := ctx.Value(contextKey{}).(string)
+
+	return responseID
+}
+
+// NewUUID returns generator which generates random (version 4) UUIDs.
+func NewUUID() Generator {
+	return func(_ *Request) (string, error) {
+		var u [16]byte
+		if _, err := rand.Read(u[:]); err != nil {
+			return "", errors.WithStack(err)
+		}
+
+		u[6] = (u[6] & 0x0f) | 0x40
+		u[8] = (u[8] & 0x3f) | 0x80
+
+		return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]), nil
+	}
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns generator which generates ULIDs (lexicographically sortable, see https://github.com/ulid/spec).
+func NewULID() Generator {
+	return func(_ *Request) (string, error) {
+		var u [16]byte
+		binary.BigEndian.PutUint64(u[0:8], uint64(time.Now().UnixMilli())<<16)
+		if _, err := rand.Read(u[6:]); err != nil {
+			return "", errors.WithStack(err)
+		}
+
+		// 128 bits are encoded as 26 characters of 5 bits each (the first character only holds 3 bits)
+		hi := binary.BigEndian.Uint64(u[0:8])
+		lo := binary.BigEndian.Uint64(u[8:16])
+
+		encoded := make([]byte, 26)
+		for i := 25; i >= 0; i-- {
+			encoded[i] = crockford[lo&0x1f]
+			lo = (lo >> 5) | (hi << 59)
+			hi >>= 5
+		}
+
+		return string(encoded), nil
+	}
+}
+
+// NewFromRequest returns generator which uses the id of the request as responseID. If the request has no id
+// given fallback generator is used.
+func NewFromRequest(fallback Generator) Generator {
+	return func(req *Request) (string, error) {
+		if req.ID != "" {
+			return req.ID, nil
+		}
+
+		if fallback == nil {
+			return "", errors.New("request has no id and no fallback generator given")
+		}
+
+		return fallback(req)
+	}
+}
@@ -0,0 +1,54 @@
+package responseid_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/responseid"
+)
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, "", responseid.FromContext(context.Background()))
+	assert.Equal(t, "rsp-1", responseid.FromContext(responseid.NewContext(context.Background(), "rsp-1")))
+}
+
+func TestNewUUID(t *testing.T) {
+	id, err := responseid.NewUUID()(&responseid.Request{})
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+}
+
+func TestNewULID(t *testing.T) {
+	generator := responseid.NewULID()
+
+	first, err := generator(&responseid.Request{})
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`), first)
+
+	time.Sleep(2 * time.Millisecond)
+
+	second, err := generator(&responseid.Request{})
+	require.NoError(t, err)
+	assert.Less(t, first, second)
+}
+
+func TestNewFromRequest(t *testing.T) {
+	id, err := responseid.NewFromRequest(nil)(&responseid.Request{ID: "who-1234567890"})
+	assert.NoError(t, err)
+	assert.Equal(t, "who-1234567890", id)
+
+	id, err = responseid.NewFromRequest(nil)(&responseid.Request{})
+	assert.ErrorContains(t, err, "request has no id and no fallback generator given")
+	assert.Equal(t, "", id)
+
+	id, err = responseid.NewFromRequest(func(_ *responseid.Request) (string, error) {
+		return "fallback", nil
+	})(&responseid.Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, "fallback", id)
+}
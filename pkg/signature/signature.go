@@ -0,0 +1,168 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/corbado/webhook-go/pkg/webhookerr"
+)
+
+// The signature is the hex encoded HMAC-SHA256 of "<timestamp>.<body>" where timestamp is the Unix time in
+// seconds, both are sent in headers.
+const (
+	DefaultSignatureHeader = "X-Corbado-Signature"
+	DefaultTimestampHeader = "X-Corbado-Timestamp"
+	DefaultMaxSkew         = 5 * time.Minute
+)
+
+type headers struct {
+	signature string
+	timestamp string
+}
+
+func newHeaders(signatureHeader string, timestampHeader string) headers {
+	if signatureHeader == "" {
+		signatureHeader = DefaultSignatureHeader
+	}
+
+	if timestampHeader == "" {
+		timestampHeader = DefaultTimestampHeader
+	}
+
+	return headers{
+		signature: http.CanonicalHeaderKey(signatureHeader),
+		timestamp: http.CanonicalHeaderKey(timestampHeader),
+	}
+}
+
+type Verifier struct {
+	secret  []byte
+	headers headers
+	maxSkew time.Duration
+}
+
+// NewVerifier returns new verifier for request signatures created with given secret. Empty headers default
+// to DefaultSignatureHeader and DefaultTimestampHeader, zero maxSkew (maximum difference between the
+// timestamp of the request and the current time) defaults to DefaultMaxSkew.
+func NewVerifier(secret string, signatureHeader string, timestampHeader string, maxSkew time.Duration) (*Verifier, error) {
+	if secret == "" {
+		return nil, errors.New("empty parameter secret")
+	}
+
+	if maxSkew < 0 {
+		return nil, errors.Errorf("invalid parameter maxSkew (%s)", maxSkew)
+	}
+
+	if maxSkew == 0 {
+		maxSkew = DefaultMaxSkew
+	}
+
+	return &Verifier{
+		secret:  []byte(secret),
+		headers: newHeaders(signatureHeader, timestampHeader),
+		maxSkew: maxSkew,
+	}, nil
+}
+
+// Verification is a started verification of a single request, the body must be written to it before
+// calling Verify().
+type Verification struct {
+	mac       hash.Hash
+	signature []byte
+}
+
+// Begin starts the verification of a request with given headers, it returns an error (resulting in status
+// code 401) if the headers are missing or invalid or the timestamp is outside the allowed window.
+func (v *Verifier) Begin(header http.Header, now time.Time) (*Verification, error) {
+	signatureValue := header.Get(v.headers.signature)
+	if signatureValue == "" {
+		return nil, unauthorized("%s header missing or empty", v.headers.signature)
+	}
+
+	signature, err := hex.DecodeString(signatureValue)
+	if err != nil || len(signature) != sha256.Size {
+		return nil, unauthorized("%s header is not a valid signature", v.headers.signature)
+	}
+
+	timestampValue := header.Get(v.headers.timestamp)
+	if timestampValue == "" {
+		return nil, unauthorized("%s header missing or empty", v.headers.timestamp)
+	}
+
+	timestamp, err := strconv.ParseInt(timestampValue, 10, 64)
+	if err != nil {
+		return nil, unauthorized("%s header is not a valid Unix timestamp", v.headers.timestamp)
+	}
+
+	skew := now.Sub(time.Unix(timestamp, 0))
+	if skew > v.maxSkew || skew < -v.maxSkew {
+		return nil, unauthorized("%s header is outside of the allowed window", v.headers.timestamp)
+	}
+
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(timestampValue + "."))
+
+	return &Verification{
+		mac:       mac,
+		signature: signature,
+	}, nil
+}
+
+// Write writes the given part of the body to the verification.
+func (v *Verification) Write(p []byte) (int, error) {
+	return v.mac.Write(p)
+}
+
+// Verify verifies the signature against the written body, it returns an error (resulting in status
+// code 401) if the signature does not match.
+func (v *Verification) Verify() error {
+	if !hmac.Equal(v.mac.Sum(nil), v.signature) {
+		return unauthorized("Invalid signature")
+	}
+
+	return nil
+}
+
+type Signer struct {
+	secret  []byte
+	headers headers
+}
+
+// NewSigner returns new signer which creates request signatures with given secret (for tests and local
+// tools), empty headers default to DefaultSignatureHeader and DefaultTimestampHeader.
+func NewSigner(secret string, signatureHeader string, timestampHeader string) (*Signer, error) {
+	if secret == "" {
+		return nil, errors.New("empty parameter secret")
+	}
+
+	return &Signer{
+		secret:  []byte(secret),
+		headers: newHeaders(signatureHeader, timestampHeader),
+	}, nil
+}
+
+// Sign returns the signature of given body with given timestamp.
+func (s *Signer) Sign(timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest sets the signature and timestamp headers for given body on given request.
+func (s *Signer) SignRequest(r *http.Request, timestamp time.Time, body []byte) {
+	r.Header.Set(s.headers.signature, s.Sign(timestamp, body))
+	r.Header.Set(s.headers.timestamp, strconv.FormatInt(timestamp.Unix(), 10))
+}
+
+func unauthorized(message string, args ...any) error {
+	return webhookerr.New(http.StatusUnauthorized, "").Wrap(errors.Errorf(message, args...))
+}
@@ -0,0 +1,94 @@
+package signature_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/corbado/webhook-go/pkg/signature"
+	"github.com/corbado/webhook-go/pkg/webhookerr"
+)
+
+const secret = "signatureSecret"
+
+func TestVerifier(t *testing.T) {
+	verifier, err := signature.NewVerifier(secret, "", "", time.Minute)
+	require.NoError(t, err)
+
+	signer, err := signature.NewSigner(secret, "", "")
+	require.NoError(t, err)
+
+	now := time.Unix(1677628800, 0)
+	body := []byte(`{"id":"who-1234567890"}`)
+
+	newRequest := func(timestamp time.Time) *http.Request {
+		r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+		require.NoError(t, err)
+
+		signer.SignRequest(r, timestamp, body)
+
+		return r
+	}
+
+	r := newRequest(now)
+	assert.Equal(t, "1677628800", r.Header.Get("X-Corbado-Timestamp"))
+	assert.Equal(t, signer.Sign(now, body), r.Header.Get("X-Corbado-Signature"))
+
+	verification, err := verifier.Begin(r.Header, now.Add(30*time.Second))
+	require.NoError(t, err)
+
+	_, err = verification.Write(body[:10])
+	require.NoError(t, err)
+	_, err = verification.Write(body[10:])
+	require.NoError(t, err)
+	assert.NoError(t, verification.Verify())
+
+	verification, err = verifier.Begin(r.Header, now)
+	require.NoError(t, err)
+
+	_, err = verification.Write([]byte(strings.Replace(string(body), "1234", "4321", 1)))
+	require.NoError(t, err)
+	assertUnauthorized(t, verification.Verify(), "Invalid signature")
+
+	_, err = verifier.Begin(r.Header, now.Add(2*time.Minute))
+	assertUnauthorized(t, err, "X-Corbado-Timestamp header is outside of the allowed window")
+
+	_, err = verifier.Begin(newRequest(now.Add(2*time.Minute)).Header, now)
+	assertUnauthorized(t, err, "X-Corbado-Timestamp header is outside of the allowed window")
+
+	r.Header.Del("X-Corbado-Signature")
+	_, err = verifier.Begin(r.Header, now)
+	assertUnauthorized(t, err, "X-Corbado-Signature header missing or empty")
+}
+
+func TestCustomHeaders(t *testing.T) {
+	verifier, err := signature.NewVerifier(secret, "x-signature", "x-timestamp", 0)
+	require.NoError(t, err)
+
+	signer, err := signature.NewSigner(secret, "X-Signature", "X-Timestamp")
+	require.NoError(t, err)
+
+	r, err := http.NewRequest(http.MethodPost, "/webhook", nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	signer.SignRequest(r, now, []byte("body"))
+
+	verification, err := verifier.Begin(r.Header, now.Add(4*time.Minute))
+	require.NoError(t, err)
+
+	_, err = verification.Write([]byte("body"))
+	require.NoError(t, err)
+	assert.NoError(t, verification.Verify())
+}
+
+func assertUnauthorized(t *testing.T, err error, message string) {
+	webhookErr, ok := webhookerr.As(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, webhookErr.StatusCode())
+	assert.ErrorContains(t, err, message)
+}
@@ -0,0 +1,110 @@
+package webhookerr
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Error is an error which can be returned by callbacks (and action handlers) to control the HTTP status
+// code and the public message of the webhook response. It can be wrapped, the handlers find it with
+// errors.As().
+type Error struct {
+	statusCode int
+	message    string
+	retryAfter time.Duration
+	cause      error
+}
+
+var _ error = &Error{}
+
+// New returns new error with given HTTP status code and public message. If message is empty the
+// status text of the status code is used.
+func New(statusCode int, message string) *Error {
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+
+	return &Error{
+		statusCode: statusCode,
+		message:    message,
+	}
+}
+
+// BadRequest returns new error which results in status code 400 with given public message.
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, message)
+}
+
+// Unavailable returns new error which results in status code 503, retryAfter is sent as Retry-After
+// header if greater than zero.
+func Unavailable(retryAfter time.Duration) *Error {
+	err := New(http.StatusServiceUnavailable, "Service temporarily unavailable")
+	err.retryAfter = retryAfter
+
+	return err
+}
+
+// TooManyRequests returns new error which results in status code 429 with given public message, retryAfter
+// is sent as Retry-After header if greater than zero.
+func TooManyRequests(message string, retryAfter time.Duration) *Error {
+	err := New(http.StatusTooManyRequests, message)
+	err.retryAfter = retryAfter
+
+	return err
+}
+
+// Internal returns new error which results in status code 500 with given public message.
+func Internal(message string) *Error {
+	return New(http.StatusInternalServerError, message)
+}
+
+// Wrap returns a copy of the error with given cause attached. The cause is not part of the public message,
+// it is only logged.
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.cause = cause
+
+	return &wrapped
+}
+
+// Error returns the error message (public message and cause).
+func (e *Error) Error() string {
+	if e.cause == nil {
+		return fmt.Sprintf("%d %s", e.statusCode, e.message)
+	}
+
+	return fmt.Sprintf("%d %s: %s", e.statusCode, e.message, e.cause.Error())
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// StatusCode returns the HTTP status code of the error.
+func (e *Error) StatusCode() int {
+	return e.statusCode
+}
+
+// Message returns the public message of the error.
+func (e *Error) Message() string {
+	return e.message
+}
+
+// RetryAfter returns the duration after which the request can be retried (zero if not set).
+func (e *Error) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// As returns the first Error in the chain of given error.
+func As(err error) (*Error, bool) {
+	var webhookErr *Error
+	if errors.As(err, &webhookErr) {
+		return webhookErr, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,47 @@
+package webhookerr_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/corbado/webhook-go/pkg/webhookerr"
+)
+
+func TestConstructors(t *testing.T) {
+	err := webhookerr.BadRequest("malformed username")
+	assert.Equal(t, http.StatusBadRequest, err.StatusCode())
+	assert.Equal(t, "malformed username", err.Message())
+	assert.Equal(t, "400 malformed username", err.Error())
+
+	err = webhookerr.Unavailable(30 * time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, err.StatusCode())
+	assert.Equal(t, 30*time.Second, err.RetryAfter())
+
+	err = webhookerr.TooManyRequests("", 2*time.Minute)
+	assert.Equal(t, http.StatusTooManyRequests, err.StatusCode())
+	assert.Equal(t, "Too Many Requests", err.Message())
+	assert.Equal(t, 2*time.Minute, err.RetryAfter())
+
+	err = webhookerr.New(http.StatusConflict, "")
+	assert.Equal(t, "Conflict", err.Message())
+}
+
+func TestAs(t *testing.T) {
+	_, ok := webhookerr.As(errors.New("unknown"))
+	assert.False(t, ok)
+
+	cause := errors.New("connection refused")
+	wrapped := errors.Wrap(fmt.Errorf("lookup failed: %w", webhookerr.Unavailable(time.Minute).Wrap(cause)), "callback failed")
+
+	webhookErr, ok := webhookerr.As(wrapped)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusServiceUnavailable, webhookErr.StatusCode())
+	assert.Equal(t, time.Minute, webhookErr.RetryAfter())
+	assert.True(t, errors.Is(wrapped, cause))
+	assert.Equal(t, "callback failed: lookup failed: 503 Service temporarily unavailable: connection refused", wrapped.Error())
+}
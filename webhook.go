@@ -1,11 +1,14 @@
 package corbado
 
 import (
-	"crypto/sha256"
+	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/corbado/webhook-go/pkg/action"
 	"github.com/corbado/webhook-go/pkg/callback"
+	"github.com/corbado/webhook-go/pkg/core"
+	"github.com/corbado/webhook-go/pkg/credential"
 	"github.com/corbado/webhook-go/pkg/ginhandler"
 	"github.com/corbado/webhook-go/pkg/logger"
 	"github.com/corbado/webhook-go/pkg/standardhandler"
@@ -17,11 +20,7 @@ type Webhook interface {
 }
 
 type Impl struct {
-	logger                 logger.Logger
-	usernameHash           [32]byte
-	passwordHash           [32]byte
-	authMethodsCallback    callback.AuthMethods
-	passwordVerifyCallback callback.PasswordVerify
+	core *core.Core
 }
 
 var _ Webhook = &Impl{}
@@ -54,33 +53,48 @@ func New(
 		return nil, errors.New("empty parameter passwordVerifyCallback")
 	}
 
+	authMethodsHandler, err := action.NewAuthMethods(callback.AdaptAuthMethodsContext(callback.AdaptAuthMethods(authMethodsCallback)))
+	if err != nil {
+		return nil, err
+	}
+
+	passwordVerifyHandler, err := action.NewPasswordVerify(callback.AdaptPasswordVerifyContext(callback.AdaptPasswordVerify(passwordVerifyCallback)))
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := credential.New(credential.DefaultLabel, username, password, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	return newFromConfig(&core.Config{
+		Logger:      logger,
+		Credentials: []*credential.Credential{cred},
+		Actions: map[string]action.Handler{
+			action.AuthMethods:    authMethodsHandler,
+			action.PasswordVerify: passwordVerifyHandler,
+		},
+	})
+}
+
+func newFromConfig(config *core.Config) (*Impl, error) {
+	c, err := core.New(config)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Impl{
-		logger:                 logger,
-		usernameHash:           sha256.Sum256([]byte(username)),
-		passwordHash:           sha256.Sum256([]byte(password)),
-		authMethodsCallback:    authMethodsCallback,
-		passwordVerifyCallback: passwordVerifyCallback,
+		core: c,
 	}, nil
 }
 
 // GetStandardHandler returns standard handler which can be used in standard HTTP library.
 func (i *Impl) GetStandardHandler() (*standardhandler.StandardHandler, error) {
-	return standardhandler.New(
-		i.logger,
-		i.usernameHash,
-		i.passwordHash,
-		i.authMethodsCallback,
-		i.passwordVerifyCallback,
-	)
+	return standardhandler.NewFromCore(i.core)
 }
 
 // GetGinHandler returns Gin handler which can be used in Gin Web Framework.
 func (i *Impl) GetGinHandler() (*ginhandler.GinHandler, error) {
-	return ginhandler.New(
-		i.logger,
-		i.usernameHash,
-		i.passwordHash,
-		i.authMethodsCallback,
-		i.passwordVerifyCallback,
-	)
+	return ginhandler.NewFromCore(i.core)
 }
@@ -2,23 +2,31 @@ package corbado_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	corbado "github.com/corbado/webhook-go"
+	"github.com/corbado/webhook-go/pkg/action"
+	"github.com/corbado/webhook-go/pkg/credential"
 	"github.com/corbado/webhook-go/pkg/dto/authmethodsresponse"
+	"github.com/corbado/webhook-go/pkg/dto/passwordverifyrequest"
 	"github.com/corbado/webhook-go/pkg/logger"
 )
 
 const username = "webhookUsername"
 const password = "webhookPassword"
+const rotatedPassword = "rotatedWebhookPassword"
+const expiredPassword = "expiredWebhookPassword"
 
 func TestHandler(t *testing.T) {
 	webhook, err := corbado.
@@ -28,6 +36,9 @@ func TestHandler(t *testing.T) {
 		SetPassword(password).
 		SetAuthMethodsCallback(authMethodsCallback).
 		SetPasswordVerifyCallback(passwordVerifyCallback).
+		RegisterAction("echo", echoAction(t)).
+		AddCredential(newCredential(t, "rotated", rotatedPassword, time.Time{}, time.Time{})).
+		AddCredential(newCredential(t, "expired", expiredPassword, time.Time{}, time.Now().Add(-time.Hour))).
 		Build()
 	require.NoError(t, err)
 	require.NotNil(t, webhook)
@@ -44,7 +55,7 @@ func TestHandler(t *testing.T) {
 
 	ginRouter := gin.New()
 	ginRouter.Use(gin.Recovery())
-	ginRouter.POST("/webhook", ginHandler.Handle)
+	ginRouter.Any("/webhook", ginHandler.Handle)
 
 	tests := []struct {
 		name                string
@@ -83,6 +94,22 @@ func TestHandler(t *testing.T) {
 				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 			},
 		},
+		{
+			name: "Expired credential",
+			createRequest: func() (*http.Request, error) {
+				r, err := http.NewRequest("POST", "/webhook", nil)
+				if err != nil {
+					return nil, err
+				}
+
+				r.SetBasicAuth(username, expiredPassword)
+
+				return r, nil
+			},
+			assert: func(resp *http.Response) {
+				assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			},
+		},
 		{
 			name: "Invalid method",
 			createRequest: func() (*http.Request, error) {
@@ -100,9 +127,8 @@ func TestHandler(t *testing.T) {
 
 				body, err := io.ReadAll(resp.Body)
 				assert.NoError(t, err)
-				assert.Equal(t, "Invalid method 'GET', only POST is allowed", string(body))
+				assert.Equal(t, `{"responseID":"","requestID":"","error":{"code":"bad_request","message":"Invalid method 'GET', only POST is allowed"}}`, string(body))
 			},
-			skipGinHandler: true,
 		},
 		{
 			name: "Missing action",
@@ -121,7 +147,7 @@ func TestHandler(t *testing.T) {
 
 				body, err := io.ReadAll(resp.Body)
 				assert.NoError(t, err)
-				assert.Equal(t, "X-Corbado-Action header missing or empty", string(body))
+				assert.Equal(t, `{"responseID":"","requestID":"","error":{"code":"bad_request","message":"X-Corbado-Action header missing or empty"}}`, string(body))
 			},
 		},
 		{
@@ -147,7 +173,28 @@ func TestHandler(t *testing.T) {
 
 				body, err := io.ReadAll(resp.Body)
 				assert.NoError(t, err)
-				assert.Equal(t, "Invalid action given in X-Corbado-Action header ('invalidAction')", string(body))
+				assert.Equal(t, `{"responseID":"","requestID":"","error":{"code":"bad_request","message":"Invalid action given in X-Corbado-Action header ('invalidAction')"}}`, string(body))
+			},
+		},
+		{
+			name: "Success (custom action)",
+			createRequest: func() (*http.Request, error) {
+				r, err := http.NewRequest("POST", "/webhook", bytes.NewReader([]byte(`{"value":"test"}`)))
+				if err != nil {
+					return nil, err
+				}
+
+				r.SetBasicAuth(username, password)
+				r.Header.Set("X-Corbado-Action", "echo")
+
+				return r, nil
+			},
+			assert: func(resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+				body, err := io.ReadAll(resp.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, `{"value":"test"}`, string(body))
 			},
 		},
 		{
@@ -179,6 +226,28 @@ func TestHandler(t *testing.T) {
 				assert.Equal(t, expectedBody, body)
 			},
 		},
+		{
+			name: "Success (rotated credential)",
+			createRequest: func() (*http.Request, error) {
+				body, err := os.ReadFile("testdata/authMethodsRequest.json")
+				if err != nil {
+					return nil, err
+				}
+
+				r, err := http.NewRequest("POST", "/webhook", bytes.NewReader(body))
+				if err != nil {
+					return nil, err
+				}
+
+				r.SetBasicAuth(username, rotatedPassword)
+				r.Header.Set("X-Corbado-Action", "authMethods")
+
+				return r, nil
+			},
+			assert: func(resp *http.Response) {
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			},
+		},
 		{
 			name: "Success (passwordVerify)'",
 			createRequest: func() (*http.Request, error) {
@@ -243,6 +312,98 @@ func TestHandler(t *testing.T) {
 	}
 }
 
+func TestHandlerContextCallback(t *testing.T) {
+	webhook, err := corbado.
+		NewBuilder().
+		SetLogger(logger.NewNull()).
+		SetUsername(username).
+		SetPassword(password).
+		SetAuthMethodsCallback(authMethodsCallback).
+		SetPasswordVerifyContextCallback(passwordVerifyContextCallback).
+		Build()
+	require.NoError(t, err)
+
+	standardHandler, err := webhook.GetStandardHandler()
+	require.NoError(t, err)
+
+	ginHandler, err := webhook.GetGinHandler()
+	require.NoError(t, err)
+
+	gin.SetMode(gin.ReleaseMode)
+
+	ginRouter := gin.New()
+	ginRouter.Any("/webhook", ginHandler.Handle)
+
+	tests := []struct {
+		name         string
+		projectID    string
+		expectedBody string
+	}{
+		{
+			name:         "Success",
+			projectID:    "pro-1234567890",
+			expectedBody: `{"responseID":"","data":{"success":true}}`,
+		},
+		{
+			name:         "Failure (other project)",
+			projectID:    "pro-0987654321",
+			expectedBody: `{"responseID":"","data":{"success":false}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, handler := range []http.Handler{standardHandler, ginRouter} {
+				body := `{"id":"who-1234567890","projectID":"` + test.projectID + `","action":"passwordVerify","data":{"username":"testUsername","password":"testPassword"}}`
+
+				r, err := http.NewRequest("POST", "/webhook", bytes.NewReader([]byte(body)))
+				require.NoError(t, err)
+
+				r.SetBasicAuth(username, password)
+				r.Header.Set("X-Corbado-Action", "passwordVerify")
+
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, r)
+
+				assert.Equal(t, http.StatusOK, rr.Code)
+				assert.Equal(t, test.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func newCredential(t *testing.T, label string, password string, notBefore time.Time, notAfter time.Time) *credential.Credential {
+	cred, err := credential.New(label, username, password, notBefore, notAfter)
+	require.NoError(t, err)
+
+	return cred
+}
+
+type echo struct {
+	Value string `json:"value"`
+}
+
+func echoAction(t *testing.T) action.Handler {
+	handler, err := action.New(
+		func(body io.Reader) (*echo, error) {
+			req := &echo{}
+			if err := json.NewDecoder(body).Decode(req); err != nil {
+				return nil, err
+			}
+
+			return req, nil
+		},
+		nil,
+		func(_ context.Context, req *echo) (*echo, error) {
+			return req, nil
+		},
+		action.EncodeJSON[*echo],
+	)
+	require.NoError(t, err)
+
+	return handler
+}
+
 func authMethodsCallback(_ string) (authmethodsresponse.Status, error) {
 	return authmethodsresponse.StatusExists, nil
 }
@@ -250,3 +411,11 @@ func authMethodsCallback(_ string) (authmethodsresponse.Status, error) {
 func passwordVerifyCallback(_ string, _ string) (bool, error) {
 	return true, nil
 }
+
+func passwordVerifyContextCallback(ctx context.Context, req *passwordverifyrequest.DTO) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	return req.ProjectID == "pro-1234567890", nil
+}